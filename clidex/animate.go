@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+	"strings"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// defaultFrameDelay is used for gifs that ask for 0 or 1 hundredths, same as browsers do
+const defaultFrameDelay = 100 * time.Millisecond
+
+// animation is a gif already rendered to text, one string per frame
+type animation struct {
+	frames []string
+	delays []time.Duration
+	loops  int // 0 means forever
+}
+
+// animatedURL picks the animated gif for a pokemon. showdown has them for
+// almost everything, the black/white ones only go up to gen 5
+func animatedURL(sprites pokeapi.Sprites, shiny bool, v spriteVariant) string {
+	sets := []pokeapi.SpriteSet{sprites.Other["showdown"]}
+	if bw := sprites.Versions["generation-v"]["black-white"].Animated; bw != nil {
+		sets = append(sets, *bw)
+	}
+	return pickSpriteURL(sets, shiny, v)
+}
+
+// decodeAnimation decodes a gif and renders every frame through the sprite pipeline.
+// gif frames are only the bit that changed, so they get drawn onto a canvas
+// first, and every frame is cropped to the same box so they line up
+func decodeAnimation(data []byte, opts renderOptions) (*animation, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("gif has no frames")
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	var snapshots []*image.NRGBA
+	var bounds image.Rectangle
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneNRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		snapshot := cloneNRGBA(canvas)
+		snapshots = append(snapshots, snapshot)
+		bounds = bounds.Union(trimBounds(snapshot))
+
+		// clean up for the next frame
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	if bounds.Empty() {
+		return nil, fmt.Errorf("gif is fully transparent")
+	}
+
+	anim := &animation{loops: g.LoopCount}
+	for i, snapshot := range snapshots {
+		anim.frames = append(anim.frames, renderImage(snapshot, bounds, opts))
+		delay := defaultFrameDelay
+		if i < len(g.Delay) && g.Delay[i] > 1 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		anim.delays = append(anim.delays, delay)
+	}
+	return anim, nil
+}
+
+func cloneNRGBA(img *image.NRGBA) *image.NRGBA {
+	clone := image.NewNRGBA(img.Rect)
+	copy(clone.Pix, img.Pix)
+	return clone
+}
+
+// playAnimation draws the first frame (next to the box, unless boxLines is nil)
+// and then keeps redrawing just the sprite columns in place until the gif runs
+// out of loops or ctx is cancelled. piped output only gets the first frame
+func playAnimation(ctx context.Context, anim *animation, boxLines []string) {
+	first := strings.TrimRight(anim.frames[0], "\n")
+	height := len(strings.Split(first, "\n"))
+	if boxLines != nil {
+		// the box might have gone underneath, either way it's all below the top row
+		lines := sideBySideLines(first, boxLines)
+		for _, line := range lines {
+			fmt.Println(paint(line))
+		}
+		height = len(lines)
+	} else {
+		fmt.Print(paint(first) + "\n")
+	}
+	if len(anim.frames) < 2 || !isTerminal(os.Stdout) {
+		return
+	}
+
+	fmt.Print("\033[?25l")       // hide cursor
+	defer fmt.Print("\033[?25h") // and always give it back, Ctrl-C included
+
+	// LoopCount -1 means play once, n means n extra times
+	plays := anim.loops + 1
+	if anim.loops < 0 {
+		plays = 1
+	}
+
+	frame := 0
+	timer := time.NewTimer(anim.delays[0])
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		frame++
+		if frame == len(anim.frames) {
+			frame = 0
+			if anim.loops != 0 {
+				plays--
+				if plays == 0 {
+					return
+				}
+			}
+		}
+
+		// jump back to the top of the block, redraw the sprite rows and go back
+		// down past whatever of the box is left below them. the box never moves
+		lines := strings.Split(strings.TrimRight(anim.frames[frame], "\n"), "\n")
+		var out strings.Builder
+		fmt.Fprintf(&out, "\033[%dA", height)
+		for _, line := range lines {
+			out.WriteString("\r" + paint(line) + "\n")
+		}
+		if rest := height - len(lines); rest > 0 {
+			fmt.Fprintf(&out, "\033[%dB", rest)
+		}
+		fmt.Print(out.String())
+
+		timer.Reset(anim.delays[frame])
+	}
+}
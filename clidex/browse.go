@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// browseOptions is what `dex browse` needs from the flags
+type browseOptions struct {
+	fetch      fetchOptions
+	info       infoOptions
+	timeout    time.Duration // per lookup, the browser itself runs until you quit
+	fixedWidth bool          // --width was given, don't follow resizes
+}
+
+// browsePanels are the tabs in the box next to the sprite
+var browsePanels = []string{"Info", "Stats", "Evolutions", "Moves"}
+
+// dexKey is one loaded (or loading) page
+type dexKey struct {
+	id    int
+	shiny bool
+}
+
+// browsePage is everything fetched for one pokemon, ready to draw
+type browsePage struct {
+	res    *lookup
+	evo    []string
+	errMsg string // set when the pokemon itself couldn't be fetched
+}
+
+// pageResult is a finished load coming back to the browser loop
+type pageResult struct {
+	key   dexKey
+	query string // what was searched for, "" when paging by number
+	page  *browsePage
+}
+
+// browser is the state of `dex browse`. only the loop in runBrowse touches
+// it, loads happen in goroutines and come back on loaded
+type browser struct {
+	ctx    context.Context
+	client *pokeapi.Client
+	opts   browseOptions
+
+	id    int
+	shiny bool
+	panel int
+
+	pages   map[dexKey]*browsePage
+	loading map[dexKey]bool
+	loaded  chan pageResult
+
+	searching     bool
+	search        string
+	pendingSearch string
+	message       string
+}
+
+// runBrowse handles `dex browse [name]`: a full screen dex you page through
+// one national dex number at a time
+func runBrowse(ctx context.Context, client *pokeapi.Client, args []string, opts browseOptions) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		fmt.Println("dex browse needs a terminal")
+		return
+	}
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		fmt.Println("Couldn't set up the terminal:", err)
+		return
+	}
+	defer restore()
+
+	// alternate screen, hidden cursor and no line wrapping (long sprite rows
+	// would push everything down). all undone on the way out
+	fmt.Print("\033[?1049h\033[?25l\033[?7l")
+	defer fmt.Print("\033[?7h\033[?25h\033[?1049l")
+
+	b := &browser{
+		ctx:     ctx,
+		client:  client,
+		opts:    opts,
+		id:      1,
+		shiny:   opts.fetch.shiny,
+		pages:   make(map[dexKey]*browsePage),
+		loading: make(map[dexKey]bool),
+		loaded:  make(chan pageResult, 8),
+	}
+	if len(args) > 0 {
+		b.find(strings.Join(args, " "))
+	} else {
+		b.show()
+	}
+
+	keys := make(chan string, 16)
+	go readKeys(os.Stdin, keys)
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+
+	for {
+		b.draw()
+		select {
+		case <-ctx.Done():
+			return
+		case key, ok := <-keys:
+			if !ok || !b.handleKey(key) {
+				return
+			}
+		case res := <-b.loaded:
+			b.finishLoad(res)
+		case <-resized:
+			if !opts.fixedWidth {
+				termWidth = detectTermWidth()
+			}
+		}
+	}
+}
+
+// handleKey does whatever key means, false means quit
+func (b *browser) handleKey(key string) bool {
+	if b.searching {
+		switch key {
+		case "enter":
+			b.searching = false
+			if query := strings.TrimSpace(b.search); query != "" {
+				b.find(query)
+			}
+		case "esc", "ctrl-c":
+			b.searching = false
+		case "backspace":
+			if r := []rune(b.search); len(r) > 0 {
+				b.search = string(r[:len(r)-1])
+			}
+		default:
+			if len([]rune(key)) == 1 {
+				b.search += key
+			}
+		}
+		return true
+	}
+
+	b.message = ""
+	switch key {
+	case "q", "esc", "ctrl-c", "ctrl-d":
+		return false
+	case "down", "j":
+		b.step(1)
+	case "up", "k":
+		b.step(-1)
+	case "pgdn":
+		b.step(10)
+	case "pgup":
+		b.step(-10)
+	case "home", "g":
+		b.step(-nationalDexMax)
+	case "end", "G":
+		b.step(nationalDexMax)
+	case "tab", "right", "l":
+		b.panel = (b.panel + 1) % len(browsePanels)
+	case "shift-tab", "left", "h":
+		b.panel = (b.panel + len(browsePanels) - 1) % len(browsePanels)
+	case "s":
+		b.shiny = !b.shiny
+		b.show()
+	case "r":
+		// forget a failed page and try again
+		key := dexKey{b.id, b.shiny}
+		if page := b.pages[key]; page != nil && page.errMsg != "" {
+			delete(b.pages, key)
+		}
+		b.show()
+	case "/":
+		b.searching = true
+		b.search = ""
+	}
+	return true
+}
+
+// step moves n pokemon along the national dex, stopping at either end.
+// forms have ids past the end, those step from the species they belong to
+func (b *browser) step(n int) {
+	id := b.id
+	if page := b.pages[dexKey{b.id, b.shiny}]; page != nil && page.res.entry != nil && id > nationalDexMax {
+		if species, ok := pokeapi.IDFromURL(page.res.entry.Species.URL); ok {
+			id = species
+		}
+	}
+	b.id = min(max(id+n, 1), nationalDexMax)
+	b.show()
+}
+
+// find jumps to a dex number, or looks a name up and jumps to wherever it is
+func (b *browser) find(query string) {
+	if id, err := strconv.Atoi(query); err == nil {
+		b.id = min(max(id, 1), nationalDexMax)
+		b.show()
+		return
+	}
+	b.pendingSearch = query
+	b.message = "Searching for " + query + "..."
+	b.load(query, dexKey{0, b.shiny})
+}
+
+// show makes sure the current page and its neighbours are loaded or loading,
+// so paging up and down doesn't have to wait
+func (b *browser) show() {
+	for _, id := range []int{b.id, b.id + 1, b.id - 1} {
+		if id < 1 || (id > nationalDexMax && id != b.id) {
+			continue
+		}
+		b.load(strconv.Itoa(id), dexKey{id, b.shiny})
+	}
+}
+
+// load fetches query in the background. key.id is 0 for name searches,
+// which don't know their number until the pokemon comes back
+func (b *browser) load(query string, key dexKey) {
+	if key.id != 0 && (b.pages[key] != nil || b.loading[key]) {
+		return
+	}
+	if key.id != 0 {
+		b.loading[key] = true
+	}
+	search := ""
+	if key.id == 0 {
+		search = query
+	}
+
+	opts := b.opts.fetch
+	opts.shiny = key.shiny
+	go func() {
+		ctx := b.ctx
+		if b.opts.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, b.opts.timeout)
+			defer cancel()
+		}
+		b.loaded <- pageResult{key: key, query: search, page: loadPage(ctx, b.client, query, opts, b.opts.timeout)}
+	}()
+}
+
+// finishLoad files a finished page away, and jumps to it if it was a search
+func (b *browser) finishLoad(res pageResult) {
+	delete(b.loading, res.key)
+	key := res.key
+	if entry := res.page.res.entry; entry != nil && key.id == 0 {
+		key.id = entry.ID
+	}
+	if key.id != 0 {
+		b.pages[key] = res.page
+	}
+
+	if res.query == "" || res.query != b.pendingSearch {
+		return
+	}
+	b.pendingSearch = ""
+	if res.page.errMsg != "" {
+		b.message = res.page.errMsg
+		return
+	}
+	b.message = ""
+	b.id = key.id
+	b.shiny = key.shiny
+	b.show()
+}
+
+// loadPage is fetchAll plus the evolution chain, and a did-you-mean when a
+// name wasn't found
+func loadPage(ctx context.Context, client *pokeapi.Client, query string, opts fetchOptions, timeout time.Duration) *browsePage {
+	res := fetchAll(ctx, client, normalizeName(query), opts)
+	page := &browsePage{res: res}
+	if res.entryErr != nil {
+		page.errMsg = fetchErrorMessage(ctx, res.entryErr, timeout)
+		if errors.Is(res.entryErr, pokeapi.ErrNotFound) {
+			if suggestions := suggestPokemon(ctx, client, slugify(query)); len(suggestions) > 0 {
+				page.errMsg += " Did you mean: " + strings.Join(suggestions, ", ") + "?"
+			}
+		}
+		return page
+	}
+
+	switch {
+	case res.species == nil:
+		page.evo = []string{"No evolution data"}
+	case res.species.EvolutionChain.URL == "":
+		page.evo = []string{"Doesn't evolve"}
+	default:
+		if chain, err := client.GetEvolutionChain(ctx, res.species.EvolutionChain.URL); err == nil {
+			page.evo = evoTreeLines(chain.Chain, res.species.Name)
+		} else {
+			page.evo = []string{"Couldn't fetch evolution chain"}
+		}
+	}
+	return page
+}
+
+// draw redraws the whole screen: tabs along the top, the sprite and panel in
+// the middle and the status/search line at the bottom
+func (b *browser) draw() {
+	rows := 24
+	if ws, ok := terminalSize(os.Stdout); ok && ws.rows > 0 {
+		rows = int(ws.rows)
+	}
+
+	header := fmt.Sprintf(" #%04d", b.id)
+	if b.shiny {
+		header += " \033[93m★\033[0m"
+	}
+	header += "  "
+	for i, name := range browsePanels {
+		if i == b.panel {
+			header += "\033[7m " + name + " \033[0m"
+		} else {
+			header += " " + name + " "
+		}
+	}
+	lines := []string{header, ""}
+	lines = append(lines, b.body()...)
+
+	// whatever doesn't fit is cut off, the status line always gets the last row
+	if len(lines) > rows-1 {
+		lines = lines[:rows-1]
+	}
+	for len(lines) < rows-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, b.status())
+
+	var out strings.Builder
+	out.WriteString("\033[H")
+	for i, line := range lines {
+		out.WriteString(paint(line) + "\033[0m\033[K")
+		if i < len(lines)-1 {
+			out.WriteString("\r\n")
+		}
+	}
+	fmt.Print(out.String())
+}
+
+// body is the sprite with the current panel boxed up next to it
+func (b *browser) body() []string {
+	page := b.pages[dexKey{b.id, b.shiny}]
+	switch {
+	case page == nil && b.pendingSearch != "":
+		return nil // the status line says what we're searching for
+	case page == nil:
+		return []string{fmt.Sprintf(" Loading #%d...", b.id)}
+	case page.errMsg != "":
+		return []string{" " + page.errMsg, "", " \033[90mr to retry\033[0m"}
+	}
+
+	res := page.res
+	sprite := res.sprite
+	if res.spriteErr != nil {
+		sprite = spriteArt{text: "No sprite available"}
+	}
+	description := res.species
+	if description == nil {
+		description = &pokeapi.SpeciesData{}
+	}
+
+	accent := currentTheme.accent(entryTypes(res.entry))
+	info := b.opts.info
+	info.accent = accent
+	info.wrap = descWrap(sprite.cols())
+
+	var panel []string
+	switch browsePanels[b.panel] {
+	case "Info":
+		panel = headerLines(res.entry, description, info)
+	case "Stats":
+		width := minDescWrap + descLabel
+		panel = statLines(res.entry.Stats, width)
+		if profile, err := defensiveProfile(entryTypes(res.entry)); err == nil {
+			panel = append(panel, "")
+			panel = append(panel, matchupLines(profile, width)...)
+		}
+	case "Evolutions":
+		panel = page.evo
+	case "Moves":
+		panel = moveLines(res.entry.Moves)
+	}
+	return sideBySideLines(sprite.text, buildBox(panel, accent))
+}
+
+// status is the bottom row: the search prompt, a message or the key help
+func (b *browser) status() string {
+	switch {
+	case b.searching:
+		return " /" + b.search + "\033[7m \033[0m"
+	case b.message != "":
+		return " " + b.message
+	default:
+		return " \033[90m↑/↓ page  pgup/pgdn ±10  / search  s shiny  tab panel  q quit\033[0m"
+	}
+}
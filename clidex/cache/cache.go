@@ -0,0 +1,228 @@
+// Package cache is a dead simple on-disk http response cache.
+// PokeAPI data basically never changes so there's no point downloading
+// the same pikachu json every single time we run dex.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long an entry is considered fresh before we revalidate it
+const DefaultTTL = 30 * 24 * time.Hour
+
+// Entry is one cached response, stored as a single json file on disk
+type Entry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	Body         []byte    `json:"body"` // encoding/json base64s this for us
+}
+
+// Cache stores entries in Dir, one file per request URL
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// Stats is a summary of what's on disk, for `dex cache stats`
+type Stats struct {
+	Dir     string
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// DefaultDir is $XDG_CACHE_HOME/clidex (or the OS equivalent)
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "clidex"), nil
+}
+
+// New returns a cache rooted at dir. a ttl <= 0 means DefaultTTL
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// Get looks up the entry for url. ok is false on a miss (or an unreadable file,
+// which we just treat as a miss)
+func (c *Cache) Get(url string) (entry *Entry, ok bool) {
+	e, ok := c.readFile(c.path(url))
+	if !ok || e.URL != url {
+		return nil, false
+	}
+	return e, true
+}
+
+// Put writes the entry for e.URL. it writes to a temp file first and renames
+// it into place so a Ctrl-C halfway through can't leave a broken entry
+func (c *Cache) Put(e *Entry) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	if e.StoredAt.IsZero() {
+		e.StoredAt = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(e.URL))
+}
+
+// Fresh reports whether e is still inside the TTL
+func (c *Cache) Fresh(e *Entry) bool {
+	return time.Since(e.StoredAt) < c.TTL
+}
+
+// Stats walks the cache dir and sums everything up
+func (c *Cache) Stats() (Stats, error) {
+	st := Stats{Dir: c.Dir}
+	err := c.each(func(path string, info os.FileInfo) error {
+		st.Entries++
+		st.Bytes += info.Size()
+		if e, ok := c.readFile(path); ok {
+			if !c.Fresh(e) {
+				st.Expired++
+			}
+			if st.Oldest.IsZero() || e.StoredAt.Before(st.Oldest) {
+				st.Oldest = e.StoredAt
+			}
+			if e.StoredAt.After(st.Newest) {
+				st.Newest = e.StoredAt
+			}
+		}
+		return nil
+	})
+	return st, err
+}
+
+// Prune deletes expired (and unreadable) entries, plus temp files a crashed
+// Put left behind, and returns how many went away
+func (c *Cache) Prune() (int, error) {
+	// a temp file this new might still be a Put in progress
+	removed, err := c.removeTemp(time.Minute)
+	if err != nil {
+		return removed, err
+	}
+	err = c.each(func(path string, info os.FileInfo) error {
+		if e, ok := c.readFile(path); ok && c.Fresh(e) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// Clear deletes every entry, and any temp files left behind by Put
+func (c *Cache) Clear() (int, error) {
+	removed, err := c.removeTemp(0)
+	if err != nil {
+		return removed, err
+	}
+	err = c.each(func(path string, info os.FileInfo) error {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// each calls fn for every entry file in the cache dir. a missing dir is just empty
+func (c *Cache) each(fn func(path string, info os.FileInfo) error) error {
+	dirEntries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		if err := fn(filepath.Join(c.Dir, d.Name()), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeTemp deletes the .tmp-* files Put writes before renaming them into
+// place. they only stick around if we died in between. anything younger than
+// minAge is left alone
+func (c *Cache) removeTemp(minAge time.Duration) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(c.Dir, ".tmp-*"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *Cache) readFile(path string) (*Entry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+// path hashes the url so we don't have to worry about slashes etc. in filenames
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClearAndPrune(t *testing.T) {
+	tests := []struct {
+		name        string
+		clear       bool          // Clear instead of Prune
+		tempAge     time.Duration // how old the leftover temp file is
+		wantRemoved int
+		wantLeft    []string // urls still cached afterwards
+		wantTemp    bool     // the temp file is still there
+	}{
+		{"clear takes everything", true, 0, 3, nil, false},
+		{"prune takes expired and old temp files", false, time.Hour, 2, []string{"fresh"}, false},
+		{"prune leaves a Put in progress", false, 0, 1, []string{"fresh"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(t.TempDir(), time.Hour)
+			if err := c.Put(&Entry{URL: "fresh", Body: []byte("{}")}); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Put(&Entry{URL: "stale", StoredAt: time.Now().Add(-2 * time.Hour), Body: []byte("{}")}); err != nil {
+				t.Fatal(err)
+			}
+			// what a crash between CreateTemp and Rename leaves behind
+			temp := filepath.Join(c.Dir, ".tmp-123")
+			if err := os.WriteFile(temp, []byte("{"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			modTime := time.Now().Add(-tt.tempAge)
+			os.Chtimes(temp, modTime, modTime)
+
+			var removed int
+			var err error
+			if tt.clear {
+				removed, err = c.Clear()
+			} else {
+				removed, err = c.Prune()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed %d, want %d", removed, tt.wantRemoved)
+			}
+			for _, url := range []string{"fresh", "stale"} {
+				_, ok := c.Get(url)
+				want := false
+				for _, left := range tt.wantLeft {
+					want = want || left == url
+				}
+				if ok != want {
+					t.Errorf("%s still cached = %v, want %v", url, ok, want)
+				}
+			}
+			if _, err := os.Stat(temp); (err == nil) != tt.wantTemp {
+				t.Errorf("temp file still there = %v, want %v", err == nil, tt.wantTemp)
+			}
+		})
+	}
+}
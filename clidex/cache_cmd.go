@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"clipokedex/cache"
+)
+
+// runCacheCmd handles `dex cache stats|clear|prune`
+func runCacheCmd(c *cache.Cache, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: dex cache <stats|clear|prune>")
+		return
+	}
+
+	switch args[0] {
+	case "stats":
+		st, err := c.Stats()
+		if err != nil {
+			fmt.Println("Error reading cache:", err)
+			return
+		}
+		fmt.Println("Dir:     ", st.Dir)
+		fmt.Println("Entries: ", st.Entries)
+		fmt.Println("Expired: ", st.Expired)
+		fmt.Println("Size:    ", formatBytes(st.Bytes))
+		fmt.Println("TTL:     ", c.TTL)
+		if st.Entries > 0 {
+			fmt.Println("Oldest:  ", st.Oldest.Format(time.DateTime))
+			fmt.Println("Newest:  ", st.Newest.Format(time.DateTime))
+		}
+
+	case "clear":
+		n, err := c.Clear()
+		if err != nil {
+			fmt.Println("Error clearing cache:", err)
+			return
+		}
+		fmt.Printf("Removed %d entries\n", n)
+
+	case "prune":
+		n, err := c.Prune()
+		if err != nil {
+			fmt.Println("Error pruning cache:", err)
+			return
+		}
+		fmt.Printf("Removed %d expired entries\n", n)
+
+	default:
+		fmt.Println("Unknown cache command:", args[0])
+		fmt.Println("Usage: dex cache <stats|clear|prune>")
+	}
+}
+
+// formatBytes turns 123456 into "120.6 KiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
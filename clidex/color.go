@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// colorMode is how many colors the terminal can actually show
+type colorMode int
+
+const (
+	colorNone colorMode = iota // no escapes at all
+	color16                    // the basic ANSI colors (30-37, 90-97)
+	color256                   // xterm-256 (38;5;n)
+	colorTrue                  // 24 bit (38;2;r;g;b)
+)
+
+// outputColors is what paint converts everything to. set once from --color in main
+var outputColors = colorTrue
+
+// parseColorFlag turns --color into a mode. auto looks at the environment and
+// whether stdout is a terminal, always just looks at how many colors TERM claims
+func parseColorFlag(value string) (colorMode, error) {
+	switch value {
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !isTerminal(os.Stdout) {
+			return colorNone, nil
+		}
+		return detectColorDepth(), nil
+	case "always":
+		return detectColorDepth(), nil
+	case "never":
+		return colorNone, nil
+	case "16":
+		return color16, nil
+	case "256":
+		return color256, nil
+	case "truecolor", "24bit":
+		return colorTrue, nil
+	}
+	return colorNone, fmt.Errorf("--color must be auto, always, never, 256, 16 or truecolor, not %q", value)
+}
+
+// detectColorDepth guesses from COLORTERM/TERM. there's no reliable way to ask
+// the terminal itself, this is the same guesswork everything else does
+func detectColorDepth() colorMode {
+	colorterm := strings.ToLower(os.Getenv("COLORTERM"))
+	term := strings.ToLower(os.Getenv("TERM"))
+
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return colorTrue
+	case strings.HasSuffix(term, "-direct") || os.Getenv("WT_SESSION") != "":
+		return colorTrue // windows terminal doesn't set COLORTERM
+	case strings.Contains(term, "256color"):
+		return color256
+	default:
+		// linux console, plain xterm, tmux without Tc, CI logs...
+		return color16
+	}
+}
+
+// isTerminal reports whether f is a terminal and not a pipe/file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// sgrRe matches a single SGR ("set graphics rendition") escape, the only kind we emit
+var sgrRe = regexp.MustCompile(`\x1b\[([\d;]*)m`)
+
+// paint converts every color escape in s to outputColors. everything we draw
+// is written in truecolor and squashed down here right before printing
+func paint(s string) string {
+	return recolorAnsi(s, outputColors)
+}
+
+// recolorAnsi rewrites the SGR escapes in s for the given mode: truecolor and
+// 256 colors get quantized to the nearest color the terminal has, and
+// colorNone drops escapes entirely
+func recolorAnsi(s string, mode colorMode) string {
+	if mode == colorTrue {
+		return s
+	}
+	if mode == colorNone {
+		return sgrRe.ReplaceAllString(s, "")
+	}
+
+	return sgrRe.ReplaceAllStringFunc(s, func(seq string) string {
+		params := strings.Split(sgrRe.FindStringSubmatch(seq)[1], ";")
+		var out []string
+		for i := 0; i < len(params); i++ {
+			p := params[i]
+			if (p != "38" && p != "48") || i+1 >= len(params) {
+				out = append(out, p)
+				continue
+			}
+
+			var c color.NRGBA
+			switch {
+			case params[i+1] == "2" && i+4 < len(params):
+				c = color.NRGBA{R: atoiByte(params[i+2]), G: atoiByte(params[i+3]), B: atoiByte(params[i+4]), A: 255}
+				i += 4
+			case params[i+1] == "5" && i+2 < len(params):
+				n := atoiByte(params[i+2])
+				i += 2
+				if mode == color256 {
+					out = append(out, p, "5", strconv.Itoa(int(n))) // already fine
+					continue
+				}
+				c = xterm256Palette[n]
+			default:
+				out = append(out, p)
+				continue
+			}
+			out = append(out, colorParams(c, mode, p == "48")...)
+		}
+		return "\x1b[" + strings.Join(out, ";") + "m"
+	})
+}
+
+// colorParams is the SGR params for c as a foreground (or background) color in mode
+func colorParams(c color.NRGBA, mode colorMode, background bool) []string {
+	base := "38"
+	if background {
+		base = "48"
+	}
+	switch mode {
+	case color256:
+		return []string{base, "5", strconv.Itoa(nearest256(c))}
+	case color16:
+		i := nearestIndex(c, ansi16Palette)
+		code := 30 + i
+		if i >= 8 {
+			code = 90 + i - 8 // the bright ones
+		}
+		if background {
+			code += 10
+		}
+		return []string{strconv.Itoa(code)}
+	default:
+		return []string{base, "2", strconv.Itoa(int(c.R)), strconv.Itoa(int(c.G)), strconv.Itoa(int(c.B))}
+	}
+}
+
+func atoiByte(s string) uint8 {
+	n, _ := strconv.Atoi(s)
+	return uint8(min(max(n, 0), 255))
+}
+
+// ansi16Palette is xterm's default take on the 16 basic colors. every
+// terminal theme changes these, so this is only ever an approximation
+var ansi16Palette = []color.NRGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// cubeLevels are the 6 steps each channel gets in the xterm 6x6x6 color cube
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// xterm256Palette is every xterm-256 color: the 16 basic ones, the 6x6x6
+// cube (16-231) and the 24 step gray ramp (232-255)
+var xterm256Palette = func() []color.NRGBA {
+	p := make([]color.NRGBA, 0, 256)
+	p = append(p, ansi16Palette...)
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				p = append(p, color.NRGBA{cubeLevels[r], cubeLevels[g], cubeLevels[b], 255})
+			}
+		}
+	}
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		p = append(p, color.NRGBA{v, v, v, 255})
+	}
+	return p
+}()
+
+// nearest256 picks the closest cube or gray color. the first 16 are skipped
+// on purpose since themes remap them and we'd never know what they look like
+func nearest256(c color.NRGBA) int {
+	best, bestDist := 16, -1
+	for i := 16; i < 256; i++ {
+		if d := colorDistance(c, xterm256Palette[i]); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// nearestIndex is the index of the palette color closest to c
+func nearestIndex(c color.NRGBA, palette []color.NRGBA) int {
+	best, bestDist := 0, -1
+	for i, p := range palette {
+		if d := colorDistance(c, p); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// colorDistance is the "redmean" approximation of how different two colors
+// look -- way closer to what eyes do than plain rgb distance, and still cheap
+func colorDistance(a, b color.NRGBA) int {
+	rmean := (int(a.R) + int(b.R)) / 2
+	dr := int(a.R) - int(b.R)
+	dg := int(a.G) - int(b.G)
+	db := int(a.B) - int(b.B)
+	return ((512+rmean)*dr*dr)>>8 + 4*dg*dg + ((767-rmean)*db*db)>>8
+}
+
+// quantizePalette is the set of colors a sprite can end up as in mode,
+// nil means anything goes
+func quantizePalette(mode colorMode) []color.NRGBA {
+	switch mode {
+	case color256:
+		return xterm256Palette[16:]
+	case color16:
+		return ansi16Palette
+	}
+	return nil
+}
+
+// ditherGrid does Floyd-Steinberg dithering of the opaque pixels onto the
+// palette for mode, so gradients turn into a pattern of nearby colors instead
+// of flat bands. transparent pixels are left alone and don't soak up any error
+func ditherGrid(grid *pixelGrid, mode colorMode) {
+	palette := quantizePalette(mode)
+	if palette == nil {
+		return
+	}
+
+	// working copy in floats so the error can push values past 0-255 for a bit
+	work := make([][3]float64, len(grid.px))
+	for i, p := range grid.px {
+		work[i] = [3]float64{float64(p.R), float64(p.G), float64(p.B)}
+	}
+
+	spread := func(x, y int, errs [3]float64, weight float64) {
+		if x < 0 || x >= grid.w || y >= grid.h || grid.px[y*grid.w+x].A < alphaCutoff {
+			return
+		}
+		for c := 0; c < 3; c++ {
+			work[y*grid.w+x][c] += errs[c] * weight
+		}
+	}
+
+	for y := 0; y < grid.h; y++ {
+		for x := 0; x < grid.w; x++ {
+			i := y*grid.w + x
+			if grid.px[i].A < alphaCutoff {
+				continue
+			}
+			old := color.NRGBA{clampByte(work[i][0]), clampByte(work[i][1]), clampByte(work[i][2]), 255}
+			picked := palette[nearestIndex(old, palette)]
+			errs := [3]float64{
+				work[i][0] - float64(picked.R),
+				work[i][1] - float64(picked.G),
+				work[i][2] - float64(picked.B),
+			}
+			grid.px[i] = color.NRGBA{picked.R, picked.G, picked.B, grid.px[i].A}
+
+			spread(x+1, y, errs, 7.0/16)
+			spread(x-1, y+1, errs, 3.0/16)
+			spread(x, y+1, errs, 5.0/16)
+			spread(x+1, y+1, errs, 1.0/16)
+		}
+	}
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(min(max(v+0.5, 0), 255))
+}
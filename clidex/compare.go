@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// runCompareCmd handles `dex compare <a> <b>`: types and base stats side by
+// side, the better stat of each pair in bold
+func runCompareCmd(ctx context.Context, client *pokeapi.Client, args []string, timeout time.Duration) {
+	args, ok := splitPair(ctx, client, args)
+	if !ok {
+		fmt.Println("Usage: dex compare <pokemon> <pokemon>")
+		fmt.Println("       (names with spaces: dex compare mr mime vs jynx)")
+		return
+	}
+
+	// both at once, same as fetchAll
+	entries := make([]*pokeapi.DexEntry, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, arg := range args {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries[i], errs[i] = client.GetPokemon(ctx, normalizeName(arg).Pokemon)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			fmt.Println(args[i]+":", fetchErrorMessage(ctx, err, timeout))
+			return
+		}
+	}
+
+	for _, line := range compareLines(entries[0], entries[1]) {
+		fmt.Println(paint(line))
+	}
+}
+
+// splitPair works out which words are the first pokemon and which the second,
+// since names like "mr mime" and "type null" have spaces in them. "vs" or a
+// comma splits them explicitly, otherwise every split point gets tried
+// against the (cached) pokemon list
+func splitPair(ctx context.Context, client *pokeapi.Client, words []string) ([]string, bool) {
+	if a, b, found := strings.Cut(strings.Join(words, " "), ","); found {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		return []string{a, b}, a != "" && b != ""
+	}
+	for i, w := range words {
+		if strings.EqualFold(w, "vs") || strings.EqualFold(w, "vs.") {
+			a, b := strings.Join(words[:i], " "), strings.Join(words[i+1:], " ")
+			return []string{a, b}, a != "" && b != ""
+		}
+	}
+	if len(words) == 2 {
+		return words, true
+	}
+	if len(words) < 2 {
+		return nil, false
+	}
+
+	names, err := client.ListPokemon(ctx)
+	if err != nil {
+		return nil, false
+	}
+	known := make(map[string]bool)
+	for _, name := range names {
+		known[name] = true
+	}
+	for i := 1; i < len(words); i++ {
+		a, b := strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+		if known[normalizeName(a).Pokemon] && known[normalizeName(b).Pokemon] {
+			return []string{a, b}, true
+		}
+	}
+	return nil, false
+}
+
+// compareLines is the table for runCompareCmd
+func compareLines(a, b *pokeapi.DexEntry) []string {
+	width := max(len(a.Name), len(b.Name), len("electric/fighting"))
+	row := func(label, left, right string) string {
+		// pad by hand, the escapes would throw off %-*s
+		pad := func(s string) string { return s + strings.Repeat(" ", width-displayWidth(s)) }
+		return strings.TrimRight(fmt.Sprintf("%-6s%s  %s", label, pad(left), pad(right)), " ")
+	}
+	stats := func(e *pokeapi.DexEntry) map[string]int {
+		m := make(map[string]int)
+		for _, s := range e.Stats {
+			m[s.Stat.Name] = s.BaseStat
+		}
+		return m
+	}
+	// bold whichever side is higher, ties stay plain
+	values := func(x, y int) (string, string) {
+		left, right := fmt.Sprintf("%3d", x), fmt.Sprintf("%3d", y)
+		switch {
+		case x > y:
+			left = "\033[1m" + left + "\033[0m"
+		case y > x:
+			right = "\033[1m" + right + "\033[0m"
+		}
+		return left, right
+	}
+
+	lines := []string{
+		row("", strings.ToUpper(a.Name), strings.ToUpper(b.Name)),
+		row("Type", strings.Join(entryTypes(a), "/"), strings.Join(entryTypes(b), "/")),
+	}
+	statsA, statsB := stats(a), stats(b)
+	totalA, totalB := 0, 0
+	for _, st := range statOrder {
+		totalA += statsA[st.name]
+		totalB += statsB[st.name]
+		left, right := values(statsA[st.name], statsB[st.name])
+		lines = append(lines, row(st.label, left, right))
+	}
+	left, right := values(totalA, totalB)
+	lines = append(lines, row("Tot", left, right))
+	return lines
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"clipokedex/cache"
+)
+
+// config is the defaults everything starts from. later sources win:
+//
+//	built in defaults < config file < CLIDEX_* env vars < flags
+//
+// the file and env vars are applied before the flags are defined, so they
+// just become the flag defaults and flag.Parse does the last step for free
+type config struct {
+	Language        string
+	Theme           string // border style, "" leaves it to theme.json
+	SpriteSource    string // auto, colorscripts or pokeapi
+	ColorscriptsURL string
+	Render          string
+	Color           string
+	CacheTTL        time.Duration
+	Timeout         time.Duration
+	Wrap            int // narrowest the description gets wrapped
+	Gap             int // columns between the sprite and the box
+}
+
+func defaultConfig() config {
+	return config{
+		Language:        "en",
+		SpriteSource:    "auto",
+		ColorscriptsURL: colorscriptsURL,
+		Render:          "halfblock",
+		Color:           "auto",
+		CacheTTL:        cache.DefaultTTL,
+		Timeout:         15 * time.Second,
+		Wrap:            35,
+		Gap:             4,
+	}
+}
+
+// configKey is one setting as it's named in the config file, the env and the flags
+type configKey struct {
+	name string // in the config file
+	env  string
+	flag string // "" if there's no flag for it
+	get  func(c *config) string
+	set  func(c *config, v string) error
+}
+
+var configKeys = []configKey{
+	{"language", "CLIDEX_LANG", "lang",
+		func(c *config) string { return c.Language },
+		func(c *config, v string) error { c.Language = v; return nil }},
+	{"theme", "CLIDEX_THEME", "theme",
+		func(c *config) string { return c.Theme },
+		func(c *config, v string) error { c.Theme = v; return nil }},
+	{"sprite_source", "CLIDEX_SPRITE_SOURCE", "source",
+		func(c *config) string { return c.SpriteSource },
+		func(c *config, v string) error { c.SpriteSource = v; return nil }},
+	{"colorscripts_url", "CLIDEX_COLORSCRIPTS_URL", "",
+		func(c *config) string { return c.ColorscriptsURL },
+		func(c *config, v string) error { c.ColorscriptsURL = strings.TrimSuffix(v, "/"); return nil }},
+	{"render", "CLIDEX_RENDER", "render",
+		func(c *config) string { return c.Render },
+		func(c *config, v string) error { c.Render = v; return nil }},
+	{"color", "CLIDEX_COLOR", "color",
+		func(c *config) string { return c.Color },
+		func(c *config, v string) error { c.Color = v; return nil }},
+	{"cache_ttl", "CLIDEX_CACHE_TTL", "cache-ttl",
+		func(c *config) string { return c.CacheTTL.String() },
+		func(c *config, v string) (err error) { c.CacheTTL, err = time.ParseDuration(v); return err }},
+	{"timeout", "CLIDEX_TIMEOUT", "timeout",
+		func(c *config) string { return c.Timeout.String() },
+		func(c *config, v string) (err error) { c.Timeout, err = time.ParseDuration(v); return err }},
+	{"wrap", "CLIDEX_WRAP", "",
+		func(c *config) string { return strconv.Itoa(c.Wrap) },
+		func(c *config, v string) (err error) { c.Wrap, err = strconv.Atoi(v); return err }},
+	{"gap", "CLIDEX_GAP", "",
+		func(c *config) string { return strconv.Itoa(c.Gap) },
+		func(c *config, v string) (err error) { c.Gap, err = strconv.Atoi(v); return err }},
+}
+
+// configPath is $XDG_CONFIG_HOME/clidex/config
+func configPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "clidex", "config"), nil
+}
+
+// loadConfig builds the config from the defaults, the file at path and the
+// env. sources says where each setting came from, for `dex config show`.
+// a broken file or env var is reported but doesn't stop the others
+func loadConfig(path string) (cfg config, sources map[string]string, errs []error) {
+	cfg = defaultConfig()
+	sources = make(map[string]string)
+	for _, key := range configKeys {
+		sources[key.name] = "default"
+	}
+
+	// the file is JSON: {"language": "fr", "timeout": "30s", "wrap": 40}
+	if data, err := os.ReadFile(path); err == nil {
+		var file map[string]any
+		if err := json.Unmarshal(data, &file); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		for name, value := range file {
+			key, ok := findConfigKey(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, name))
+				continue
+			}
+			if err := key.set(&cfg, fmt.Sprint(value)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+				continue
+			}
+			sources[name] = "config"
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+	}
+
+	for _, key := range configKeys {
+		value, ok := os.LookupEnv(key.env)
+		if !ok {
+			continue
+		}
+		if err := key.set(&cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key.env, err))
+			continue
+		}
+		sources[key.name] = "env " + key.env
+	}
+	return cfg, sources, errs
+}
+
+func findConfigKey(name string) (configKey, bool) {
+	for _, key := range configKeys {
+		if key.name == name {
+			return key, true
+		}
+	}
+	return configKey{}, false
+}
+
+// applyFlags copies the settings given as flags back into cfg, so cfg is
+// what's actually in effect
+func applyFlags(cfg *config, sources map[string]string) {
+	flag.Visit(func(f *flag.Flag) {
+		for _, key := range configKeys {
+			if key.flag == f.Name && key.set(cfg, f.Value.String()) == nil {
+				sources[key.name] = "flag --" + f.Name
+			}
+		}
+	})
+}
+
+// runConfigCmd handles `dex config show`
+func runConfigCmd(cfg config, sources map[string]string, args []string) {
+	if len(args) < 1 || args[0] != "show" {
+		fmt.Println("Usage: dex config show")
+		return
+	}
+
+	if path, err := configPath(); err == nil {
+		fmt.Println("Config file:", path)
+	}
+	width := 0
+	for _, key := range configKeys {
+		width = max(width, len(key.name))
+	}
+	for _, key := range configKeys {
+		value := key.get(&cfg)
+		if value == "" {
+			value = `""`
+		}
+		fmt.Printf("  %-*s  %-30s (%s)\n", width, key.name, value, sources[key.name])
+	}
+}
@@ -1,138 +1,456 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"image"
-	_ "image/png"
-	"io"
-	"net/http"
+	"math/rand/v2"
+	"os"
+	"os/signal"
 	"regexp"
+	"strconv"
 	"strings"
-)
-
-type DexEntry struct {
-	Name    string        `json:"name"`
-	ID      int           `json:"id"`
-	Types   []PokemonType `json:"types"`
-	Sprites Sprites       `json:"sprites"`
-}
+	"time"
 
-type Sprites struct {
-	FrontDefault string `json:"front_default"`
-	FrontShiny   string `json:"front_shiny"`
-}
+	"clipokedex/cache"
+	"clipokedex/pokeapi"
+)
 
-// Go needs multiple structs for multi level json
-type PokemonType struct {
-	Type TypeName `json:"type"`
-}
+func main() {
+	// config file and env first, they're the defaults the flags start from
+	var cfg config
+	var cfgSources map[string]string
+	var cfgErrs []error
+	if path, err := configPath(); err == nil {
+		cfg, cfgSources, cfgErrs = loadConfig(path)
+	} else {
+		cfg, cfgSources, cfgErrs = loadConfig("")
+	}
+	for _, err := range cfgErrs {
+		fmt.Fprintln(os.Stderr, "Ignoring config:", err)
+	}
 
-type TypeName struct {
-	Name string `json:"name"`
-}
+	shiny := flag.Bool("shiny", false, "Show shiny variant")
+	offline := flag.Bool("offline", false, "Only use cached data, never hit the network")
+	noCache := flag.Bool("no-cache", false, "Don't read or write the response cache")
+	cacheTTL := flag.Duration("cache-ttl", cfg.CacheTTL, "How long cached responses stay fresh before revalidating")
+	spriteOnly := flag.Bool("sprite-only", false, "Only print the sprite, no info box")
+	infoOnly := flag.Bool("info-only", false, "Only print the info box, don't fetch a sprite at all")
+	output := flag.String("output", "text", "Output format: text, json or yaml")
+	autoMatch := flag.Bool("auto-match", false, "If the name isn't found, just go with the closest match")
+	evo := flag.Bool("evo", false, "Also show the evolution chain")
+	timeout := flag.Duration("timeout", cfg.Timeout, "Give up on the whole lookup after this long (0 for no limit)")
+	random := flag.Bool("random", false, "Show a random pokemon instead of a named one")
+	daily := flag.Bool("daily", false, "Show the pokemon of the day (same for everyone on the same --seed)")
+	seed := flag.String("seed", "", "Extra seed for --daily, share it with your team")
+	gen := flag.Int("gen", 0, "Only pick --random/--daily pokemon from this generation")
+	typeFilter := flag.String("type", "", "Only pick --random/--daily pokemon with this type")
+	legendary := flag.Bool("legendary", false, "Only pick legendary/mythical pokemon for --random/--daily")
+	size := flag.Int("size", 0, "Render the sprite ourselves at this many columns wide (skips colorscripts)")
+	renderMode := flag.String("render", cfg.Render, "How to draw rendered sprites: halfblock, quadrant, braille or ascii (anything but halfblock skips colorscripts)")
+	graphicsFlag := flag.String("graphics", "auto", "Draw the sprite as a real image: auto, kitty, sixel or none")
+	colorFlag := flag.String("color", cfg.Color, "Color output: auto, always, never, truecolor, 256 or 16")
+	dither := flag.Bool("dither", false, "Dither rendered sprites when the terminal can't do truecolor")
+	back := flag.Bool("back", false, "Show the back sprite")
+	female := flag.Bool("female", false, "Show the female sprite, for pokemon that look different")
+	artwork := flag.Bool("artwork", false, "Show the official artwork instead of the game sprite")
+	game := flag.String("game", "", "Show the sprite and description from one game, e.g. red-blue, crystal or scarlet")
+	lang := flag.String("lang", cfg.Language, "Description language: en, ja, fr, de, es, it, ko...")
+	allEntries := flag.Bool("all-entries", false, "Show every different description, with the games each one is from")
+	form := flag.String("form", "", "Show a regional/alternate form, e.g. alola, galar, gmax (see dex forms <name>)")
+	mega := flag.Bool("mega", false, "Show the mega evolution (add --form x/y for charizard and mewtwo)")
+	themeFlag := flag.String("theme", cfg.Theme, "Box border style: rounded, square, double, ascii or none (default from theme.json, else rounded)")
+	source := flag.String("source", cfg.SpriteSource, "Where sprites come from: auto (colorscripts, then PokeAPI), colorscripts or pokeapi")
+	widthFlag := flag.Int("width", 0, "Lay things out for a terminal this many columns wide (default: ask the terminal)")
+	animate := flag.Bool("animate", false, "Play the animated sprite in place until it ends or Ctrl-C (text rendering only)")
+	shinyChance := flag.Float64("shiny-chance", defaultShinyChance, "Chance a --random/--daily pick is shiny (0-1)")
+	flag.Parse()
+	applyFlags(&cfg, cfgSources)
 
-type SpeciesData struct {
-	FlavorTextEntries []FlavorTextEntry `json:"flavor_text_entries"`
-}
+	args := flag.Args() // non-flag arguments
+	rolling := *random || *daily
+	if len(args) < 1 && !rolling {
+		fmt.Println("Usage: dex <--shiny> <--sprite-only|--info-only> <pokemon name>")
+		fmt.Println("       dex <--random|--daily> <--gen n> <--type t> <--legendary>")
+		fmt.Println("       dex evo <pokemon name>")
+		fmt.Println("       dex forms <pokemon name>")
+		fmt.Println("       dex compare <pokemon> <pokemon>")
+		fmt.Println("       dex browse [pokemon name]")
+		fmt.Println("       dex repl")
+		fmt.Println("       dex types <type>[/<type>]")
+		fmt.Println("       dex cache <stats|clear|prune>")
+		fmt.Println("       dex config show")
+		return
+	}
 
-type FlavorTextEntry struct {
-	FlavorText string   `json:"flavor_text"`
-	Language   Language `json:"language"`
-}
+	if *spriteOnly && *infoOnly {
+		fmt.Println("--sprite-only and --info-only don't go together")
+		return
+	}
+	if *random && *daily {
+		fmt.Println("--random and --daily don't go together")
+		return
+	}
+	if *output != "text" && *output != "json" && *output != "yaml" {
+		fmt.Println("--output must be text, json or yaml")
+		return
+	}
+	if _, ok := cellRenderers[*renderMode]; !ok {
+		fmt.Println("--render must be halfblock, quadrant, braille or ascii")
+		return
+	}
+	colors, err := parseColorFlag(*colorFlag)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	outputColors = colors
 
-type Language struct {
-	Name string `json:"name"`
-}
+	// theme.json first, --theme beats its border
+	if path, err := themePath(); err == nil {
+		t, err := loadTheme(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Ignoring theme file:", err)
+		} else {
+			currentTheme = t
+		}
+	}
+	if *themeFlag != "" {
+		if err := currentTheme.setBorder(*themeFlag); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 
-func main() {
-	shiny := flag.Bool("shiny", false, "Show shiny variant")
-	flag.Parse()
+	sideGap = max(cfg.Gap, 1)
+	minDescWrap = max(cfg.Wrap, minStacked)
+	termWidth = *widthFlag
+	if termWidth <= 0 {
+		termWidth = detectTermWidth()
+	}
 
-	args := flag.Args() // non-flag arguments
-	if len(args) < 1 {
-		fmt.Println("Usage: dex <--shiny> <pokemon name>")
+	if *source != "auto" && *source != "colorscripts" && *source != "pokeapi" {
+		fmt.Println("--source must be auto, colorscripts or pokeapi")
 		return
 	}
+	graphics := *graphicsFlag
+	switch graphics {
+	case "auto":
+		graphics = detectGraphics()
+	case "kitty", "sixel", "none":
+	default:
+		fmt.Println("--graphics must be auto, kitty, sixel or none")
+		return
+	}
+
+	// json/yaml are for scripts: no chatter on stdout, errors on stderr and a real exit code
+	structured := *output != "text"
+
+	// Ctrl-C cancels any in-flight requests instead of just killing the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// --animate plays for as long as it likes, only Ctrl-C stops it
+	playCtx := ctx
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	name := args[0]
-	fmt.Println("Searching for:", strings.ToLower(name)+"...")
+	client := pokeapi.NewClient()
+	client.Offline = *offline
 
-	// ---------- POKEMON INFO FETCH ----------
-	reqInfo := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon/%s", name)
-	resInfo, errInfo := http.Get(reqInfo)
+	// cache lives under $XDG_CACHE_HOME/clidex
+	var respCache *cache.Cache
+	if cacheDir, err := cache.DefaultDir(); err == nil {
+		respCache = cache.New(cacheDir, *cacheTTL)
+	} else {
+		fmt.Fprintln(os.Stderr, "Couldn't find a cache dir:", err)
+	}
+
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
 
-	if errInfo != nil {
-		fmt.Println("Error fetching data:", errInfo)
+	// subcommands that don't need the network at all
+	if command == "types" {
+		runTypesCmd(args[1:])
 		return
 	}
+	if command == "config" {
+		runConfigCmd(cfg, cfgSources, args[1:])
+		return
+	}
+	if command == "cache" {
+		if respCache == nil {
+			return
+		}
+		runCacheCmd(respCache, args[1:])
+		return
+	}
+	if !*noCache {
+		client.Cache = respCache
+	}
+
+	render := renderOptions{
+		width:  *size,
+		mode:   *renderMode,
+		colors: outputColors,
+		dither: *dither,
+	}
+	variant := spriteVariant{back: *back, female: *female, artwork: *artwork, game: *game}
 
-	defer resInfo.Body.Close()
-	if resInfo.StatusCode == 404 {
-		fmt.Println("Could not find that pokemon...")
+	switch command {
+	case "evo":
+		runEvoCmd(ctx, client, args[1:], *timeout)
+		return
+	case "forms":
+		runFormsCmd(ctx, client, args[1:], *timeout)
+		return
+	case "compare":
+		runCompareCmd(ctx, client, args[1:], *timeout)
+		return
+	case "repl":
+		// the repl catches Ctrl-C per command, main's handler would cancel
+		// everything for good the first time
+		stop()
+		runRepl(context.Background(), client, replOptions{
+			fetch: fetchOptions{
+				shiny:    *shiny,
+				render:   render,
+				graphics: graphics,
+				animate:  *animate,
+				source:   *source,
+				csURL:    cfg.ColorscriptsURL,
+				variant:  variant,
+			},
+			show:    showOptions{info: infoOptions{lang: *lang, game: *game, allEntries: *allEntries}},
+			timeout: *timeout,
+		})
+		return
+	case "browse":
+		// the browser runs until you quit, each lookup gets its own --timeout
+		runBrowse(playCtx, client, args[1:], browseOptions{
+			fetch: fetchOptions{
+				shiny:    *shiny,
+				render:   render,
+				graphics: "none",
+				source:   *source,
+				csURL:    cfg.ColorscriptsURL,
+				variant:  variant,
+			},
+			info:       infoOptions{lang: *lang, game: *game},
+			timeout:    *timeout,
+			fixedWidth: *widthFlag > 0,
+		})
 		return
 	}
 
-	bodyInfo, errInfo := io.ReadAll(resInfo.Body)
+	var name dexName
+	if rolling {
+		pool, err := candidatePool(ctx, client, randomFilter{gen: *gen, typeName: *typeFilter, legendary: *legendary})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		if *daily {
+			rng = dailyRand(*seed)
+		}
+		id, rolledShiny := pickPokemon(rng, pool, *shinyChance)
+		name = normalizeName(strconv.Itoa(id))
+		*shiny = *shiny || rolledShiny
+	} else {
+		name = normalizeName(args[0])
+	}
 
-	// ---------- SPECIES INFO FETCH ----------
-	reqSpeciesInfo := fmt.Sprintf("https://pokeapi.co/api/v2/pokemon-species/%s", name)
-	resSpeciesInfo, errSpeciesInfo := http.Get(reqSpeciesInfo)
+	formName := *form
+	if *mega {
+		formName = strings.TrimSuffix("mega-"+formName, "-")
+	}
+	if formName != "" {
+		var err error
+		name, err = resolveForm(ctx, client, name, formName)
+		if err != nil {
+			msg := fetchErrorMessage(ctx, err, *timeout)
+			if structured {
+				fmt.Fprintln(os.Stderr, msg)
+				os.Exit(1)
+			}
+			fmt.Println(msg)
+			return
+		}
+	}
+	// the only-modes get embedded in motds and scripts, keep them clean
+	chatty := !*spriteOnly && !*infoOnly && !structured
+	if chatty && rolling {
+		fmt.Println("Rolled: #" + name.Pokemon + "...")
+	} else if chatty {
+		fmt.Println("Searching for:", name.Pokemon+"...")
+	}
 
-	if errSpeciesInfo != nil {
-		fmt.Println("Error fetching data:", errSpeciesInfo)
+	opts := fetchOptions{
+		shiny:       *shiny,
+		skipSprite:  *infoOnly || structured,
+		skipSpecies: *spriteOnly && !*evo,
+		render:      render,
+		graphics:    graphics,
+		animate:     *animate,
+		source:      *source,
+		csURL:       cfg.ColorscriptsURL,
+		variant:     variant,
+	}
+	res := fetchAll(ctx, client, name, opts)
+
+	// probably a typo -- look for something close and maybe just go with it
+	var suggestions []string
+	if errors.Is(res.entryErr, pokeapi.ErrNotFound) && !rolling {
+		suggestions = suggestPokemon(ctx, client, name.Pokemon)
+		if *autoMatch && len(suggestions) > 0 {
+			name = normalizeName(suggestions[0])
+			suggestions = nil
+			if chatty {
+				fmt.Println("Going with:", name.Pokemon+"...")
+			}
+			res = fetchAll(ctx, client, name, opts)
+		}
+	}
+
+	// can't show anything without the pokemon itself
+	if res.entryErr != nil {
+		msg := fetchErrorMessage(ctx, res.entryErr, *timeout)
+		if len(suggestions) > 0 {
+			msg += "\nDid you mean: " + strings.Join(suggestions, ", ") + "?"
+		}
+		if structured {
+			fmt.Fprintln(os.Stderr, msg)
+			os.Exit(1)
+		}
+		fmt.Println(msg)
 		return
 	}
+	entry := res.entry
 
-	defer resSpeciesInfo.Body.Close()
-	if resSpeciesInfo.StatusCode == 404 {
-		fmt.Println("Could not find that pokemon...")
+	if structured {
+		if res.speciesErr != nil {
+			fmt.Fprintln(os.Stderr, "Couldn't fetch description:", res.speciesErr)
+			res.species = &pokeapi.SpeciesData{}
+		}
+		if err := writeRecord(os.Stdout, newDexRecord(entry, res.species, *lang, *game), *output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		return
 	}
 
-	bodySpeciesInfo, errSpeciesInfo := io.ReadAll(resSpeciesInfo.Body)
+	printLookup(playCtx, res, showOptions{
+		info:       infoOptions{lang: *lang, game: *game, allEntries: *allEntries},
+		spriteOnly: *spriteOnly,
+		infoOnly:   *infoOnly,
+	})
 
-	var description SpeciesData
-	json.Unmarshal(bodySpeciesInfo, &description)
+	if *evo && res.species != nil {
+		fmt.Println()
+		printEvolutions(ctx, client, res.species)
+	}
+}
 
-	// ---------- SPRITE FETCH ----------
-	var reqSprite string
-	if *shiny {
-		reqSprite = fmt.Sprintf("https://gitlab.com/phoneybadger/pokemon-colorscripts/-/raw/main/colorscripts/small/shiny/%s", name)
-	} else {
-		reqSprite = fmt.Sprintf("https://gitlab.com/phoneybadger/pokemon-colorscripts/-/raw/main/colorscripts/small/regular/%s", name)
+// showOptions is what printLookup shows
+type showOptions struct {
+	info       infoOptions // wrap and accent get filled in per pokemon
+	spriteOnly bool
+	infoOnly   bool
+}
+
+// printLookup prints a successful lookup: the sprite (text, image or animation)
+// with the info box beside it. ctx only stops animations
+func printLookup(ctx context.Context, res *lookup, opts showOptions) {
+	// species and sprite are optional -- say what went wrong but keep going
+	description := res.species
+	if description == nil {
+		if res.speciesErr != nil {
+			fmt.Println("Couldn't fetch description:", res.speciesErr)
+		}
+		description = &pokeapi.SpeciesData{}
+	}
+	sprite := res.sprite
+	if res.spriteErr != nil {
+		sprite = spriteArt{text: "No sprite available"}
 	}
 
-	resSprite, errSprite := http.Get(reqSprite)
-	if errSprite != nil {
-		fmt.Println("Couldn't fetch sprite:", errSprite)
+	// the box is colored by the pokemon's first type
+	var boxLines []string
+	accent := currentTheme.accent(entryTypes(res.entry))
+	if !opts.spriteOnly {
+		info := opts.info
+		info.wrap = descWrap(sprite.cols())
+		info.accent = accent
+		boxLines = buildBox(buildInfoLines(res.entry, description, info), accent)
 	}
 
-	defer resSprite.Body.Close()
+	switch {
+	case opts.spriteOnly && sprite.anim != nil:
+		playAnimation(ctx, sprite.anim, nil)
+	case opts.spriteOnly && sprite.graphic != nil:
+		printImageBeside(sprite.graphic, nil)
+	case opts.spriteOnly:
+		fmt.Print(paint(strings.TrimRight(sprite.text, "\n")) + "\n")
+	case opts.infoOnly:
+		for _, line := range boxLines {
+			fmt.Println(paint(line))
+		}
+	case sprite.anim != nil:
+		playAnimation(ctx, sprite.anim, boxLines)
+	case sprite.graphic != nil:
+		printImageBeside(sprite.graphic, boxLines)
+	default:
+		printSideBySide(sprite.text, boxLines)
+	}
+}
 
-	var entry DexEntry
-	json.Unmarshal(bodyInfo, &entry)
+// infoOptions is how buildInfoLines fills in the box
+type infoOptions struct {
+	wrap   int    // columns to wrap the description at
+	accent string // escape for the headings, see theme.accent
+	lang   string // flavor text language
+	game   string // flavor text from this game if it has one
+	// allEntries shows every distinct flavor text instead of one
+	allEntries bool
+}
 
-	// get sprite as a string
-	var sprite string
-	if resSprite.StatusCode == 200 {
-		bodySprite, _ := io.ReadAll(resSprite.Body)
-		sprite = string(bodySprite)
-	} else if !*shiny && entry.Sprites.FrontDefault != "" {
-		sprite = renderSprite(entry.Sprites.FrontDefault)
-	} else if *shiny && entry.Sprites.FrontShiny != "" {
-		sprite = renderSprite(entry.Sprites.FrontShiny)
-	} else {
-		sprite = "No sprite available"
+// buildInfoLines is everything that goes in the box next to the sprite
+func buildInfoLines(entry *pokeapi.DexEntry, description *pokeapi.SpeciesData, opts infoOptions) []string {
+	infoLines := headerLines(entry, description, opts)
+
+	// stat bars and matchups stretch to the width of everything above them
+	width := maxDisplayWidth(infoLines)
+	if stats := statLines(entry.Stats, width); stats != nil {
+		infoLines = append(infoLines, "")
+		infoLines = append(infoLines, stats...)
+	}
+
+	// type matchups come from the built in chart, no extra requests
+	if profile, err := defensiveProfile(entryTypes(entry)); err == nil {
+		infoLines = append(infoLines, "")
+		infoLines = append(infoLines, matchupLines(profile, width)...)
+	}
+
+	return infoLines
+}
+
+// headerLines is the top of the box: name, id, type and description
+func headerLines(entry *pokeapi.DexEntry, description *pokeapi.SpeciesData, opts infoOptions) []string {
+	heading := func(label string) string {
+		return opts.accent + label + "\033[0m "
 	}
 
-	// build info lines
 	var infoLines []string
-	infoLines = append(infoLines, fmt.Sprintf("Name: %s", strings.ToUpper(entry.Name)))
-	infoLines = append(infoLines, fmt.Sprintf("ID: %d", entry.ID))
+	infoLines = append(infoLines, heading("Name:")+strings.ToUpper(entry.Name))
+	infoLines = append(infoLines, heading("ID:")+strconv.Itoa(entry.ID))
 
-	typeStr := "Type: "
+	typeStr := heading("Type:")
 	for i := 0; i < len(entry.Types); i++ {
 		typeStr += strings.ToUpper(entry.Types[i].Type.Name)
 		if i != len(entry.Types)-1 {
@@ -141,46 +459,97 @@ func main() {
 	}
 	infoLines = append(infoLines, typeStr)
 
-	for i := 0; i < len(description.FlavorTextEntries); i++ {
-		if description.FlavorTextEntries[i].Language.Name == "en" {
-			infoLines = append(infoLines, "")
-			// clean up newlines/form feeds from API text and word-wrap
-			desc := strings.ReplaceAll(description.FlavorTextEntries[i].FlavorText, "\n", " ")
-			desc = strings.ReplaceAll(desc, "\f", " ")
-			// wrap so the box doesnt break
-			wrapped := wordWrap(desc, 35)
-			for j, line := range wrapped {
-				if j == 0 {
-					infoLines = append(infoLines, "Desc: "+line)
-				} else {
-					infoLines = append(infoLines, "      "+line)
-				}
+	addDesc := func(text string) {
+		infoLines = append(infoLines, "")
+		// wrap so the box doesnt break
+		wrapped := wordWrap(text, opts.wrap)
+		for j, line := range wrapped {
+			if j == 0 {
+				infoLines = append(infoLines, heading("Desc:")+line)
+			} else {
+				infoLines = append(infoLines, "      "+line)
 			}
-			break
 		}
 	}
 
-	printSideBySide(sprite, infoLines)
+	if opts.allEntries {
+		// every distinct description, each signed with the games it's from
+		for _, group := range flavorGroups(description, opts.lang) {
+			addDesc(group.text)
+			for _, line := range wordWrap("- "+strings.Join(group.versions, ", "), opts.wrap) {
+				infoLines = append(infoLines, "      \033[90m"+line+"\033[0m")
+			}
+		}
+	} else if flavor := pickFlavorText(description, opts.lang, opts.game); flavor != nil {
+		addDesc(cleanFlavorText(flavor.FlavorText))
+	}
+	return infoLines
+}
 
+// suggestPokemon looks up pokemon names close to name (the list is cached after the first time)
+func suggestPokemon(ctx context.Context, client *pokeapi.Client, name string) []string {
+	names, err := client.ListPokemon(ctx)
+	if err != nil {
+		return nil
+	}
+	return suggestNames(name, names, 5)
 }
 
+// printFetchError explains why the main pokemon lookup failed
+func printFetchError(ctx context.Context, err error, timeout time.Duration) {
+	fmt.Println(fetchErrorMessage(ctx, err, timeout))
+}
+
+// fetchErrorMessage is the human version of a failed lookup
+func fetchErrorMessage(ctx context.Context, err error, timeout time.Duration) string {
+	var formErr *formError
+	var gameErr *gameError
+	switch {
+	case errors.As(err, &formErr) && len(formErr.Have) == 0:
+		return "Could not find that form... " + formErr.Species + " only comes in one"
+	case errors.As(err, &formErr):
+		return "Could not find that form... try: " + strings.Join(formErr.Have, ", ")
+	case errors.As(err, &gameErr):
+		return "Nothing from " + gameErr.Game + "... try: " + strings.Join(gameErr.Have, ", ")
+	case errors.Is(err, pokeapi.ErrNotFound):
+		return "Could not find that pokemon..."
+	case errors.Is(err, pokeapi.ErrOffline):
+		return "Offline and that pokemon isn't cached yet..."
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Sprint("Timed out after ", timeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return "Cancelled"
+	default:
+		return fmt.Sprint("Error fetching data: ", err)
+	}
+}
+
+// ansiRe matches ANSI escape codes. wtf is this regex ;(
+var ansiRe = regexp.MustCompile(`\x1b\[[\d;]*[A-Za-z]`)
+
 // stripAnsi removes ANSI escape codes so we can measure visible string length
 func stripAnsi(s string) string {
-	// wtf is this regex ;(
-	re := regexp.MustCompile(`\x1b\[[\d;]*[A-Za-z]`)
-	return re.ReplaceAllString(s, "")
+	return ansiRe.ReplaceAllString(s, "")
 }
 
-// wordWrap splits a string into lines of at most maxWidth characters, breaking at spaces
+// wordWrap splits a string into lines of at most maxWidth columns, breaking at spaces.
+// words wider than a whole line (japanese flavor text has no spaces) get chopped up
 func wordWrap(s string, maxWidth int) []string {
 	words := strings.Fields(s)
 	var lines []string
 	currentLine := ""
 
 	for _, word := range words {
-		if currentLine == "" {
+		if displayWidth(word) > maxWidth {
+			pieces := splitWidth(word, maxWidth)
+			if currentLine != "" {
+				lines = append(lines, currentLine)
+			}
+			lines = append(lines, pieces[:len(pieces)-1]...)
+			currentLine = pieces[len(pieces)-1]
+		} else if currentLine == "" {
 			currentLine = word
-		} else if len(currentLine)+1+len(word) <= maxWidth {
+		} else if displayWidth(currentLine)+1+displayWidth(word) <= maxWidth {
 			currentLine += " " + word
 		} else {
 			lines = append(lines, currentLine)
@@ -193,43 +562,62 @@ func wordWrap(s string, maxWidth int) []string {
 	return lines
 }
 
-// displayWidth counts the visible column width of a string
+// displayWidth counts the visible column width of a string: escape codes
+// don't count, wide characters count twice and accents don't count at all
 func displayWidth(s string) int {
-	return len([]rune(stripAnsi(s)))
+	return stringWidth(stripAnsi(s))
 }
 
-// printSideBySide prints the sprite on the left and info in a box on the right
-func printSideBySide(sprite string, infoLines []string) {
-	spriteLines := strings.Split(strings.TrimRight(sprite, "\n"), "\n")
-
-	// find the widest sprite line (visible characters only)
-	spriteMaxWidth := 0
-	for _, line := range spriteLines {
-		w := displayWidth(line)
-		if w > spriteMaxWidth {
-			spriteMaxWidth = w
+// maxDisplayWidth is the visible width of the widest line
+func maxDisplayWidth(lines []string) int {
+	widest := 0
+	for _, line := range lines {
+		if w := displayWidth(line); w > widest {
+			widest = w
 		}
 	}
+	return widest
+}
 
+// buildBox wraps the info lines in a box drawn in currentTheme's border style.
+// accent colors the border (see theme.accent)
+func buildBox(infoLines []string, accent string) []string {
 	// find the widest info line to size the box
-	boxContentWidth := 0
-	for _, line := range infoLines {
-		if len([]rune(line)) > boxContentWidth {
-			boxContentWidth = len([]rune(line))
-		}
-	}
+	boxContentWidth := maxDisplayWidth(infoLines)
 	boxContentWidth += 2 // padding inside box
 
 	// build the box lines: top border, content rows, bottom border
-	white := "\033[97m" // bright white
+	b := currentTheme.border
 	reset := "\033[0m"
 	var boxLines []string
-	boxLines = append(boxLines, white+"╭"+strings.Repeat("─", boxContentWidth)+"╮"+reset)
+	boxLines = append(boxLines, accent+b.topLeft+strings.Repeat(b.horizontal, boxContentWidth)+b.topRight+reset)
 	for _, line := range infoLines {
-		padding := strings.Repeat(" ", boxContentWidth-len([]rune(line))-1)
-		boxLines = append(boxLines, white+"│ "+line+padding+"│"+reset)
+		padding := strings.Repeat(" ", boxContentWidth-displayWidth(line)-1)
+		// the line may have reset its own colors, so the accent goes back on before the border
+		boxLines = append(boxLines, accent+b.vertical+reset+" "+line+padding+accent+b.vertical+reset)
+	}
+	boxLines = append(boxLines, accent+b.bottomLeft+strings.Repeat(b.horizontal, boxContentWidth)+b.bottomRight+reset)
+	return boxLines
+}
+
+// printSideBySide prints the sprite on the left and the info box (from buildBox) on the right
+func printSideBySide(sprite string, boxLines []string) {
+	for _, line := range sideBySideLines(sprite, boxLines) {
+		fmt.Println(paint(line))
+	}
+}
+
+// sideBySideLines lays out the sprite and box next to each other, or the box
+// under the sprite when the terminal is too narrow for both
+func sideBySideLines(sprite string, boxLines []string) []string {
+	spriteLines := strings.Split(strings.TrimRight(sprite, "\n"), "\n")
+
+	// find the widest sprite line (visible characters only)
+	spriteMaxWidth := maxDisplayWidth(spriteLines)
+
+	if !fitsBeside(spriteMaxWidth, maxDisplayWidth(boxLines)) {
+		return append(spriteLines, boxLines...)
 	}
-	boxLines = append(boxLines, white+"╰"+strings.Repeat("─", boxContentWidth)+"╯"+reset)
 
 	// print lines side by side
 	totalLines := len(spriteLines)
@@ -237,8 +625,9 @@ func printSideBySide(sprite string, infoLines []string) {
 		totalLines = len(boxLines)
 	}
 
-	gap := "    " // space between sprite and box
+	gap := strings.Repeat(" ", sideGap) // space between sprite and box
 
+	var lines []string
 	for i := 0; i < totalLines; i++ {
 		spritePart := ""
 		if i < len(spriteLines) {
@@ -254,87 +643,7 @@ func printSideBySide(sprite string, infoLines []string) {
 			boxPart = boxLines[i]
 		}
 
-		fmt.Println(spritePart + padding + gap + boxPart)
+		lines = append(lines, spritePart+padding+gap+boxPart)
 	}
-}
-
-// renderSprite fetches a PNG from a URL and converts it to colored terminal art
-// using ANSI truecolor escape codes and half-block characters (▀▄)
-// each terminal character represents 2 vertical pixels
-// CAUTION: this part was largely gen'd by claude. thanks claude
-func renderSprite(url string) string {
-	res, err := http.Get(url)
-	if err != nil {
-		return "Could not fetch sprite"
-	}
-	defer res.Body.Close()
-
-	img, _, err := image.Decode(res.Body)
-	if err != nil {
-		return "Could not decode sprite"
-	}
-
-	bounds := img.Bounds()
-
-	// find the bounding box of non-transparent pixels to trim empty space
-	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X, bounds.Min.Y
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			_, _, _, a := img.At(x, y).RGBA()
-			if a >= 128 {
-				if x < minX {
-					minX = x
-				}
-				if x > maxX {
-					maxX = x
-				}
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
-			}
-		}
-	}
-
-	var result strings.Builder
-
-	// scale factor — 1 means full size, 2 means half, etc.
-	scale := 1
-
-	for y := minY; y <= maxY; y += 2 * scale {
-		for x := minX; x <= maxX; x += scale {
-			topR, topG, topB, topA := img.At(x, y).RGBA()
-			topTransparent := topA < 128
-
-			// bottom pixel may not exist if image height is odd
-			var botR, botG, botB, botA uint32
-			botTransparent := true
-			if y+scale < bounds.Max.Y {
-				botR, botG, botB, botA = img.At(x, y+scale).RGBA()
-				botTransparent = botA < 128
-			}
-
-			if topTransparent && botTransparent {
-				result.WriteString(" ")
-			} else if topTransparent {
-				// only bottom pixel visible — use ▄ with foreground color
-				br, bg, bb := botR>>8, botG>>8, botB>>8
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm▄\033[0m", br, bg, bb))
-			} else if botTransparent {
-				// only top pixel visible — use ▀ with foreground color
-				tr, tg, tb := topR>>8, topG>>8, topB>>8
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm▀\033[0m", tr, tg, tb))
-			} else {
-				// both pixels visible — ▀ foreground=top, background=bottom
-				tr, tg, tb := topR>>8, topG>>8, topB>>8
-				br, bg, bb := botR>>8, botG>>8, botB>>8
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm\033[48;2;%d;%d;%dm▀\033[0m", tr, tg, tb, br, bg, bb))
-			}
-		}
-		result.WriteString("\n")
-	}
-
-	return result.String()
+	return lines
 }
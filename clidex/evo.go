@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// runEvoCmd handles `dex evo <name>`
+func runEvoCmd(ctx context.Context, client *pokeapi.Client, args []string, timeout time.Duration) {
+	if len(args) < 1 {
+		fmt.Println("Usage: dex evo <pokemon name>")
+		return
+	}
+
+	species, err := client.GetSpecies(ctx, normalizeName(args[0]).Species)
+	if err != nil {
+		printFetchError(ctx, err, timeout)
+		return
+	}
+	printEvolutions(ctx, client, species)
+}
+
+// printEvolutions fetches the species' evolution chain and draws it as a tree
+func printEvolutions(ctx context.Context, client *pokeapi.Client, species *pokeapi.SpeciesData) {
+	if species.EvolutionChain.URL == "" {
+		fmt.Println("No evolution data")
+		return
+	}
+	chain, err := client.GetEvolutionChain(ctx, species.EvolutionChain.URL)
+	if err != nil {
+		fmt.Println("Couldn't fetch evolution chain:", err)
+		return
+	}
+
+	for _, line := range evoTreeLines(chain.Chain, species.Name) {
+		fmt.Println(paint(line))
+	}
+}
+
+// evoTreeLines draws the chain like `tree` does:
+//
+//	eevee
+//	├── vaporeon (use water-stone)
+//	└── jolteon (use thunder-stone)
+//
+// the stage matching current gets highlighted
+func evoTreeLines(root pokeapi.ChainLink, current string) []string {
+	lines := []string{evoName(root.Species.Name, current)}
+	lines = append(lines, evoChildLines(root, current, "")...)
+	return lines
+}
+
+// evoChildLines recursively draws everything under link. prefix is the
+// accumulated "│   " indentation from the levels above. the glyphs come from
+// the theme so --theme ascii gets |-- and `-- instead
+func evoChildLines(link pokeapi.ChainLink, current, prefix string) []string {
+	g := currentTheme.border
+	var lines []string
+	for i, next := range link.EvolvesTo {
+		branch, indent := g.branch, g.indent
+		if i == len(link.EvolvesTo)-1 {
+			branch, indent = g.lastBranch, strings.Repeat(" ", displayWidth(g.indent))
+		}
+
+		line := prefix + branch + evoName(next.Species.Name, current)
+		if how := describeEvolution(next.EvolutionDetails); how != "" {
+			line += " \033[90m(" + how + ")\033[0m"
+		}
+		lines = append(lines, line)
+		lines = append(lines, evoChildLines(next, current, prefix+indent)...)
+	}
+	return lines
+}
+
+// evoName bolds the pokemon we actually looked up
+func evoName(name, current string) string {
+	if name == current {
+		return "\033[1m" + strings.ToUpper(name) + "\033[0m"
+	}
+	return name
+}
+
+// describeEvolution turns evolution details into "level 16" / "use fire-stone" etc.
+// some pokemon have more than one way to evolve, those get joined with "or"
+func describeEvolution(details []pokeapi.EvolutionDetail) string {
+	var ways []string
+	for _, d := range details {
+		if w := describeDetail(d); w != "" && !slices.Contains(ways, w) {
+			ways = append(ways, w)
+		}
+	}
+	return strings.Join(ways, " or ")
+}
+
+// describeDetail describes one way of evolving, trigger first then any extra conditions
+func describeDetail(d pokeapi.EvolutionDetail) string {
+	var parts []string
+
+	switch d.Trigger.Name {
+	case "level-up":
+		if d.MinLevel != nil {
+			parts = append(parts, fmt.Sprintf("level %d", *d.MinLevel))
+		} else {
+			parts = append(parts, "level up")
+		}
+	case "use-item":
+		if d.Item != nil {
+			parts = append(parts, "use "+d.Item.Name)
+		} else {
+			parts = append(parts, "use item")
+		}
+	case "trade":
+		parts = append(parts, "trade")
+	case "":
+	default:
+		parts = append(parts, strings.ReplaceAll(d.Trigger.Name, "-", " "))
+	}
+
+	if d.MinHappiness != nil {
+		parts = append(parts, "friendship")
+	}
+	if d.MinAffection != nil {
+		parts = append(parts, "affection")
+	}
+	if d.MinBeauty != nil {
+		parts = append(parts, "beauty")
+	}
+	if d.HeldItem != nil {
+		parts = append(parts, "holding "+d.HeldItem.Name)
+	}
+	if d.KnownMove != nil {
+		parts = append(parts, "knowing "+d.KnownMove.Name)
+	}
+	if d.KnownMoveType != nil {
+		parts = append(parts, "knowing a "+d.KnownMoveType.Name+" move")
+	}
+	if d.TradeSpecies != nil {
+		parts = append(parts, "for "+d.TradeSpecies.Name)
+	}
+	if d.Location != nil {
+		parts = append(parts, "at "+d.Location.Name)
+	}
+	if d.TimeOfDay != "" {
+		parts = append(parts, "at "+d.TimeOfDay)
+	}
+	if d.Gender != nil {
+		// PokeAPI gender ids: 1 is female, 2 is male
+		if *d.Gender == 1 {
+			parts = append(parts, "female")
+		} else {
+			parts = append(parts, "male")
+		}
+	}
+	if d.NeedsOverworldRain {
+		parts = append(parts, "while raining")
+	}
+	if d.TurnUpsideDown {
+		parts = append(parts, "upside down")
+	}
+	if d.RelativePhysicalStats != nil {
+		switch *d.RelativePhysicalStats {
+		case 1:
+			parts = append(parts, "atk > def")
+		case -1:
+			parts = append(parts, "atk < def")
+		default:
+			parts = append(parts, "atk = def")
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
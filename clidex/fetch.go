@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"clipokedex/pokeapi"
+)
+
+// colorscriptsURL is where the pre-rendered pokemon-colorscripts sprites live,
+// unless the config points colorscripts_url somewhere else
+const colorscriptsURL = "https://gitlab.com/phoneybadger/pokemon-colorscripts/-/raw/main/colorscripts/small"
+
+// lookup is everything we fetched for one pokemon. any of the three fetches
+// can fail on its own -- the err fields say which ones did
+type lookup struct {
+	entry    *pokeapi.DexEntry
+	entryErr error
+
+	species    *pokeapi.SpeciesData
+	speciesErr error
+
+	sprite    spriteArt
+	spriteErr error
+}
+
+// spriteArt is a sprite ready to print: text art (colorscript or rendered),
+// a real image if the terminal does graphics, or rendered gif frames for --animate
+type spriteArt struct {
+	text    string
+	graphic *graphicImage
+	anim    *animation
+}
+
+// cols is how wide the sprite comes out on screen
+func (s spriteArt) cols() int {
+	switch {
+	case s.graphic != nil:
+		return s.graphic.cols
+	case s.anim != nil:
+		return maxDisplayWidth(strings.Split(s.anim.frames[0], "\n"))
+	default:
+		return maxDisplayWidth(strings.Split(s.text, "\n"))
+	}
+}
+
+// fetchOptions tweaks what fetchAll bothers requesting
+type fetchOptions struct {
+	shiny       bool
+	skipSprite  bool // --info-only, don't waste a sprite request
+	skipSpecies bool // --sprite-only doesn't need the description
+	render      renderOptions
+	graphics    string // "kitty", "sixel" or "none"
+	animate     bool
+	variant     spriteVariant
+	source      string // auto, colorscripts or pokeapi
+	csURL       string // colorscripts base url
+}
+
+// fetchAll fires off the pokemon, species and sprite requests at the same time
+// (minus whatever opts skips) and waits for all of them. if the pokemon request
+// fails there's nothing to show, so it cancels the others instead of letting
+// them run to the timeout
+func fetchAll(ctx context.Context, client *pokeapi.Client, name dexName, opts fetchOptions) *lookup {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var res lookup
+	var wg sync.WaitGroup
+	// closed once res.entry/res.entryErr are set, the sprite fallback needs the PNG url from it
+	entryReady := make(chan struct{})
+
+	// ---------- POKEMON INFO FETCH ----------
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(entryReady)
+		res.entry, res.entryErr = client.GetPokemon(ctx, name.Pokemon)
+		if res.entryErr != nil {
+			cancel()
+		}
+	}()
+
+	// ---------- SPECIES INFO FETCH ----------
+	if !opts.skipSpecies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.species, res.speciesErr = client.GetSpecies(ctx, name.Species)
+			if !errors.Is(res.speciesErr, pokeapi.ErrNotFound) {
+				return
+			}
+			// forms like charizard-mega-x don't have their own species, but the
+			// pokemon says which one it belongs to
+			<-entryReady
+			if res.entry != nil && res.entry.Species.Name != "" && res.entry.Species.Name != name.Species {
+				res.species, res.speciesErr = client.GetSpecies(ctx, res.entry.Species.Name)
+			}
+		}()
+	}
+
+	// ---------- SPRITE FETCH ----------
+	if !opts.skipSprite {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.sprite, res.spriteErr = fetchSprite(ctx, client, name, opts, entryReady, &res)
+		}()
+	}
+
+	wg.Wait()
+	// a species that failed to load can't say which games it's in, only
+	// check --game against it when it's here or wasn't wanted
+	if res.entry != nil && (res.species != nil || opts.skipSpecies) {
+		res.entryErr = checkGame(res.entry, res.species, opts.variant.game)
+	}
+	return &res
+}
+
+// fetchSprite tries the colorscripts repo first and falls back to rendering
+// the PokeAPI PNG ourselves. entryReady must be closed before res.entry is read
+func fetchSprite(ctx context.Context, client *pokeapi.Client, name dexName, opts fetchOptions, entryReady <-chan struct{}, res *lookup) (spriteArt, error) {
+	// --animate wants the gif, anything without one just gets the still sprite
+	if opts.animate {
+		if anim, err := fetchAnimation(ctx, client, opts, entryReady, res); err == nil {
+			return spriteArt{anim: anim}, nil
+		}
+	}
+
+	// --game picks the description too, and PokeAPI has no sprites from the
+	// newer games. those get the default sprite, so keep the colorscript for it
+	variant := opts.variant
+	if variant.game != "" {
+		entry, err := waitForEntry(ctx, entryReady, res)
+		if err != nil {
+			return spriteArt{}, err
+		}
+		if _, ok := gameSprites(entry.Sprites, variant.game); !ok {
+			variant.game = ""
+		}
+	}
+
+	// colorscripts are pre-rendered half-blocks of the plain front sprite at
+	// one size, so --size, another --render mode or any other variant always
+	// means rendering ourselves. and a real image beats any of them
+	textOnly := opts.graphics == "" || opts.graphics == "none"
+	canColorscript := textOnly && variant.plain() && opts.render.width == 0 && (opts.render.mode == "" || opts.render.mode == "halfblock")
+	if canColorscript && opts.source != "pokeapi" {
+		sprite, err := fetchColorscript(ctx, client, name, opts, entryReady, res)
+		if err == nil {
+			return spriteArt{text: sprite}, nil
+		}
+		// sprite_source = colorscripts means no PokeAPI fallback
+		if opts.source == "colorscripts" {
+			return spriteArt{}, err
+		}
+	}
+
+	// no colorscript (or gitlab is being slow) -- wait for the pokemon info for the PNG url
+	entry, err := waitForEntry(ctx, entryReady, res)
+	if err != nil {
+		return spriteArt{}, err
+	}
+
+	pngURL := pickSpriteURL(spriteSets(entry.Sprites, variant), opts.shiny, variant)
+	if pngURL == "" {
+		return spriteArt{text: "No sprite available"}, nil
+	}
+
+	data, err := client.Fetch(ctx, pngURL)
+	if err != nil {
+		return spriteArt{}, err
+	}
+	if !textOnly {
+		if graphic, err := encodeGraphic(data, opts.graphics, opts.render.width); err == nil {
+			return spriteArt{graphic: graphic}, nil
+		}
+	}
+	return spriteArt{text: renderSprite(data, opts.render)}, nil
+}
+
+// fetchAnimation grabs the animated gif for the pokemon and renders its frames
+func fetchAnimation(ctx context.Context, client *pokeapi.Client, opts fetchOptions, entryReady <-chan struct{}, res *lookup) (*animation, error) {
+	entry, err := waitForEntry(ctx, entryReady, res)
+	if err != nil {
+		return nil, err
+	}
+	gifURL := animatedURL(entry.Sprites, opts.shiny, opts.variant)
+	if gifURL == "" {
+		return nil, fmt.Errorf("no animated sprite for %s", entry.Name)
+	}
+	data, err := client.Fetch(ctx, gifURL)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAnimation(data, opts.render)
+}
+
+// fetchColorscript grabs the pre-rendered sprite from the colorscripts repo
+func fetchColorscript(ctx context.Context, client *pokeapi.Client, name dexName, opts fetchOptions, entryReady <-chan struct{}, res *lookup) (string, error) {
+	variant := "regular"
+	if opts.shiny {
+		variant = "shiny"
+	}
+	// colorscripts are only named by pokemon, so a dex number has to wait for the real name
+	if _, err := strconv.Atoi(name.Colorscript); err == nil {
+		entry, err := waitForEntry(ctx, entryReady, res)
+		if err != nil {
+			return "", err
+		}
+		name = normalizeName(entry.Name)
+	}
+	baseURL := opts.csURL
+	if baseURL == "" {
+		baseURL = colorscriptsURL
+	}
+	reqSprite := fmt.Sprintf("%s/%s/%s", baseURL, variant, name.Colorscript)
+
+	bodySprite, err := client.Fetch(ctx, reqSprite)
+	if err != nil {
+		return "", err
+	}
+	return string(bodySprite), nil
+}
+
+// waitForEntry blocks until the pokemon fetch in fetchAll is done and hands back its result
+func waitForEntry(ctx context.Context, entryReady <-chan struct{}, res *lookup) (*pokeapi.DexEntry, error) {
+	select {
+	case <-entryReady:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.entry == nil {
+		return nil, res.entryErr
+	}
+	return res.entry, nil
+}
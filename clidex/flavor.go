@@ -0,0 +1,78 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"clipokedex/pokeapi"
+)
+
+// flavorGroup is one description and every game that uses it word for word
+type flavorGroup struct {
+	text     string
+	versions []string
+}
+
+// pickFlavorText returns the flavor text entry in lang from game (see
+// matchGame), or the first one in lang if game is "" or has nothing in lang.
+// checkGame has already turned away games that don't exist. nil if there's
+// nothing in lang at all
+func pickFlavorText(species *pokeapi.SpeciesData, lang, game string) *pokeapi.FlavorTextEntry {
+	var inLang []*pokeapi.FlavorTextEntry
+	var versions []string
+	for i := range species.FlavorTextEntries {
+		if flavor := &species.FlavorTextEntries[i]; flavor.Language.Name == lang {
+			inLang = append(inLang, flavor)
+			versions = append(versions, flavor.Version.Name)
+		}
+	}
+	if len(inLang) == 0 {
+		return nil
+	}
+
+	if game != "" {
+		if version, ok := matchGame(versions, game); ok {
+			return inLang[slices.Index(versions, version)]
+		}
+	}
+	return inLang[0]
+}
+
+// flavorVersions is every game with a description, in any language
+func flavorVersions(species *pokeapi.SpeciesData) []string {
+	var versions []string
+	for _, flavor := range species.FlavorTextEntries {
+		if !slices.Contains(versions, flavor.Version.Name) {
+			versions = append(versions, flavor.Version.Name)
+		}
+	}
+	return versions
+}
+
+// flavorGroups is every distinct description in lang, in the order the API
+// lists them. a lot of games reuse the same text, those get one group
+func flavorGroups(species *pokeapi.SpeciesData, lang string) []flavorGroup {
+	var groups []flavorGroup
+	index := make(map[string]int)
+	for _, flavor := range species.FlavorTextEntries {
+		if flavor.Language.Name != lang {
+			continue
+		}
+		// the same text often only differs in where the line breaks were
+		text := strings.Join(strings.Fields(cleanFlavorText(flavor.FlavorText)), " ")
+		if i, ok := index[text]; ok {
+			groups[i].versions = append(groups[i].versions, flavor.Version.Name)
+			continue
+		}
+		index[text] = len(groups)
+		groups = append(groups, flavorGroup{text: text, versions: []string{flavor.Version.Name}})
+	}
+	return groups
+}
+
+// cleanFlavorText gets rid of the newlines/form feeds the API text is full of
+func cleanFlavorText(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "\f", " ")
+	return text
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// formAliases lets people type the adjective instead of PokeAPI's suffix
+var formAliases = map[string]string{
+	"alolan":     "alola",
+	"galarian":   "galar",
+	"hisuian":    "hisui",
+	"paldean":    "paldea",
+	"gigantamax": "gmax",
+	"g-max":      "gmax",
+}
+
+// runFormsCmd handles `dex forms <name>`
+func runFormsCmd(ctx context.Context, client *pokeapi.Client, args []string, timeout time.Duration) {
+	if len(args) < 1 {
+		fmt.Println("Usage: dex forms <pokemon name>")
+		return
+	}
+
+	species, err := client.GetSpecies(ctx, normalizeName(args[0]).Species)
+	if err != nil {
+		printFetchError(ctx, err, timeout)
+		return
+	}
+
+	width := 0
+	for _, v := range species.Varieties {
+		width = max(width, len(v.Pokemon.Name))
+	}
+
+	fmt.Println("Forms of", strings.ToUpper(species.Name)+":")
+	for _, v := range species.Varieties {
+		hint := "--form " + formSuffix(species.Name, v.Pokemon.Name)
+		if v.IsDefault {
+			hint = "(default)"
+		}
+		fmt.Printf("  %-*s  %s\n", width, v.Pokemon.Name, hint)
+	}
+}
+
+// resolveForm swaps name for the species' variety matching form ("alola",
+// "mega", "mega-y", "gmax"...). the exact suffix wins, then one that starts
+// with it (mega -> mega-x), then one that contains it
+func resolveForm(ctx context.Context, client *pokeapi.Client, name dexName, form string) (dexName, error) {
+	species, err := client.GetSpecies(ctx, name.Species)
+	if err != nil {
+		return name, err
+	}
+
+	form = slugify(form)
+	if alias, ok := formAliases[form]; ok {
+		form = alias
+	}
+
+	var suffixes []string
+	for _, v := range species.Varieties {
+		if !v.IsDefault {
+			suffixes = append(suffixes, formSuffix(species.Name, v.Pokemon.Name))
+		}
+	}
+	matches := []func(suffix string) bool{
+		func(suffix string) bool { return suffix == form },
+		func(suffix string) bool { return strings.HasPrefix(suffix, form+"-") },
+		func(suffix string) bool { return strings.Contains(suffix, form) },
+	}
+	for _, match := range matches {
+		for _, suffix := range suffixes {
+			if match(suffix) {
+				// colorscripts name forms the same way PokeAPI does, normalizeName
+				// only has to sort out the default-form ones like deoxys-attack
+				variety := normalizeName(species.Name + "-" + suffix)
+				variety.Species = species.Name
+				return variety, nil
+			}
+		}
+	}
+
+	return name, &formError{Species: species.Name, Form: form, Have: suffixes}
+}
+
+// formError is --form/--mega asking for something the species doesn't come as
+type formError struct {
+	Species string
+	Form    string
+	Have    []string // the forms it does have
+}
+
+func (e *formError) Error() string {
+	return fmt.Sprintf("%s has no %s form", e.Species, e.Form)
+}
+
+// formSuffix is the variety name minus the species, charizard-mega-x -> mega-x
+func formSuffix(species, variety string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(variety, species), "-")
+}
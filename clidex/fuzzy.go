@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggestNames finds up to n names from the list that look like what the user
+// probably meant. prefix matches ("pika" -> pikachu) win, then whatever's closest
+// by edit distance, as long as it's not so far off that it's a different pokemon
+func suggestNames(input string, names []string, n int) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return nil
+	}
+
+	// anything further than this is more of a guess than a typo
+	maxDist := len(input) / 3
+	if maxDist < 2 {
+		maxDist = 2
+	}
+
+	type candidate struct {
+		name   string
+		dist   int
+		prefix bool
+		common int // length of the shared prefix, breaks distance ties
+	}
+	var candidates []candidate
+	for _, name := range names {
+		prefix := strings.HasPrefix(name, input)
+		dist := levenshtein(input, name)
+		if prefix || dist <= maxDist {
+			candidates = append(candidates, candidate{name, dist, prefix, commonPrefixLen(input, name)})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.prefix != b.prefix {
+			return a.prefix
+		}
+		if a.dist != b.dist {
+			return a.dist < b.dist
+		}
+		// people usually get the start of the name right ("pikchu" is pikachu, not pichu)
+		if a.common != b.common {
+			return a.common > b.common
+		}
+		return a.name < b.name
+	})
+
+	var out []string
+	for i := 0; i < len(candidates) && i < n; i++ {
+		out = append(out, candidates[i].name)
+	}
+	return out
+}
+
+// levenshtein is the classic edit distance (insert/delete/substitute all cost 1).
+// only keeps two rows around since we never need the full table
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// commonPrefixLen counts how many leading bytes a and b share
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
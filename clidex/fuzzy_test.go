@@ -0,0 +1,85 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+var testNames = []string{
+	"pikachu", "pichu", "raichu", "charizard", "charmander", "charmeleon",
+	"bulbasaur", "mew", "mewtwo", "eevee",
+}
+
+func TestSuggestNames(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  []string
+	}{
+		// typos
+		{"pikachuu", 5, []string{"pikachu"}},
+		{"charmandr", 1, []string{"charmander"}},
+		{"  Bulbsaur ", 5, []string{"bulbasaur"}},
+		// same distance, the longer shared start wins: pikchu is pikachu, not pichu
+		{"pikchu", 2, []string{"pikachu", "pichu"}},
+		// prefixes come first, then distance, then alphabetical
+		{"char", 5, []string{"charizard", "charmander", "charmeleon"}},
+		{"mew", 5, []string{"mew", "mewtwo"}},
+		// short inputs still get 2 edits
+		{"mw", 5, []string{"mew"}},
+		// too far off to be a typo (8 letters only allows 2 edits)
+		{"mewthree", 5, nil},
+		{"", 5, nil},
+	}
+
+	for _, tt := range tests {
+		if got := suggestNames(tt.input, testNames, tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("suggestNames(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
+
+// --auto-match goes with the first suggestion, so it only kicks in when
+// something is inside the distance cutoff
+func TestAutoMatchCutoff(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // "" means no match, dex just says it couldn't find it
+	}{
+		{"pikchu", "pikachu"},
+		{"eeve", "eevee"},
+		{"charzard", "charizard"},
+		{"raichuuuuu", ""}, // 4 extra letters, 10 only allows 3
+		{"missingno", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if suggestions := suggestNames(tt.input, testNames, 5); len(suggestions) > 0 {
+			got = suggestions[0]
+		}
+		if got != tt.want {
+			t.Errorf("auto-match %q = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"pikachu", "pikachu", 0},
+		{"pikachu", "pikchu", 1},
+		{"pikachu", "pichu", 2},
+		{"", "mew", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"slices"
+	"strings"
+)
+
+// defaultGraphicCols is how wide a real image sprite is when --size isn't set,
+// about the same as a colorscript
+const defaultGraphicCols = 20
+
+// graphicImage is a sprite encoded for a terminal graphics protocol. it takes
+// up exactly cols x rows cells, which is what the layout needs to know
+type graphicImage struct {
+	escape     string
+	cols, rows int
+}
+
+// detectGraphics guesses whether the terminal can show real images. tmux
+// needs passthrough set up for either protocol so it's left on text art
+func detectGraphics() string {
+	if os.Getenv("TMUX") != "" || outputColors == colorNone || !isTerminal(os.Stdout) {
+		return "none"
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "":
+		return "kitty"
+	case os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return "kitty" // wezterm speaks kitty's protocol too
+	case strings.HasPrefix(term, "foot") || strings.Contains(term, "mlterm"):
+		return "sixel"
+	}
+	return "none"
+}
+
+// cellSize is how many pixels one terminal cell is. lots of terminals don't
+// report it, in which case we guess something typical
+func cellSize() (w, h int) {
+	if ws, ok := terminalSize(os.Stdout); ok && ws.rows > 0 && ws.xpixel > 0 && ws.ypixel > 0 {
+		return int(ws.xpixel) / int(ws.cols), int(ws.ypixel) / int(ws.rows)
+	}
+	return 10, 20
+}
+
+// encodeGraphic trims the PNG and encodes it for protocol ("kitty" or "sixel")
+// at cols cells wide. rows falls out of the aspect ratio
+func encodeGraphic(data []byte, protocol string, cols int) (*graphicImage, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	trimmed := trimBounds(img)
+	if trimmed.Empty() {
+		return nil, fmt.Errorf("sprite is empty")
+	}
+	if cols <= 0 {
+		cols = defaultGraphicCols
+	}
+
+	cellW, cellH := cellSize()
+	pixelW := cols * cellW
+	pixelH := pixelW * trimmed.Dy() / trimmed.Dx()
+	rows := (pixelH + cellH - 1) / cellH
+
+	switch protocol {
+	case "kitty":
+		return &graphicImage{escape: kittyEscape(img, trimmed, cols, rows), cols: cols, rows: rows}, nil
+	case "sixel":
+		grid := downsample(img, trimmed, pixelW, 1)
+		return &graphicImage{escape: sixelEscape(grid), cols: cols, rows: rows}, nil
+	}
+	return nil, fmt.Errorf("unknown graphics protocol %q", protocol)
+}
+
+// kittyEscape sends the trimmed sprite as a PNG with the kitty graphics
+// protocol and lets the terminal scale it to cols x rows. the payload has to be
+// split into 4096 byte chunks, m=1 means more are coming
+func kittyEscape(img image.Image, rect image.Rectangle, cols, rows int) string {
+	trimmed := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			trimmed.Set(x, y, img.At(rect.Min.X+x, rect.Min.Y+y))
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, trimmed)
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var out strings.Builder
+	// f=100 png, a=T transmit+display, C=1 don't move the cursor, q=2 no replies
+	first := true
+	for len(payload) > 0 {
+		chunk := payload[:min(4096, len(payload))]
+		payload = payload[len(chunk):]
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&out, "\033_Gf=100,a=T,q=2,C=1,c=%d,r=%d,m=%d;%s\033\\", cols, rows, more, chunk)
+			first = false
+		} else {
+			fmt.Fprintf(&out, "\033_Gm=%d;%s\033\\", more, chunk)
+		}
+	}
+	return out.String()
+}
+
+// sixelEscape encodes the grid as sixel graphics. sixel draws 6 pixel tall
+// bands, one pass per color per band, so colors get squashed to the xterm-256
+// palette first to keep the register count sane. transparent pixels are just
+// never drawn (the 1 in P2 says leave them as background)
+func sixelEscape(grid *pixelGrid) string {
+	// map each visible pixel to a palette index, -1 is transparent
+	idx := make([]int, len(grid.px))
+	var used []int
+	for i, p := range grid.px {
+		idx[i] = -1
+		if p.A >= alphaCutoff {
+			idx[i] = nearest256(p)
+			if !slices.Contains(used, idx[i]) {
+				used = append(used, idx[i])
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("\033P0;1;0q")
+	fmt.Fprintf(&out, "\"1;1;%d;%d", grid.w, grid.h)
+
+	// color registers, sixel wants rgb as 0-100 percentages
+	for _, c := range used {
+		p := xterm256Palette[c]
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", c, int(p.R)*100/255, int(p.G)*100/255, int(p.B)*100/255)
+	}
+
+	for band := 0; band < grid.h; band += 6 {
+		first := true
+		for _, c := range used {
+			// which of the 6 rows in this band are color c, per column
+			row := make([]byte, grid.w)
+			hasBits := false
+			for x := 0; x < grid.w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < grid.h; dy++ {
+					if idx[(band+dy)*grid.w+x] == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				hasBits = hasBits || bits != 0
+			}
+			if !hasBits {
+				continue
+			}
+			if !first {
+				out.WriteByte('$') // back to the start of the band for the next color
+			}
+			first = false
+			fmt.Fprintf(&out, "#%d", c)
+			writeSixelRun(&out, row)
+		}
+		out.WriteByte('-') // next band
+	}
+
+	out.WriteString("\033\\")
+	return out.String()
+}
+
+// writeSixelRun writes a row of sixel characters with run-length encoding
+// (!<count><char>), sprites have a lot of long runs of the same thing
+func writeSixelRun(out *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, row[i])
+		} else {
+			out.WriteString(strings.Repeat(string(row[i]), n))
+		}
+		i = j
+	}
+}
+
+// printImageBeside draws a graphics protocol image with the info box to its right (or
+// under it when there isn't room).
+// the image isn't text so the normal side by side padding can't work -- instead
+// we reserve the lines, draw the image at the top, then jump the cursor past it
+// on each line to print the box
+func printImageBeside(img *graphicImage, boxLines []string) {
+	// not enough room, the box goes under the image instead
+	top := 0
+	if !fitsBeside(img.cols, maxDisplayWidth(boxLines)) {
+		top = img.rows
+	}
+	height := max(img.rows, top+len(boxLines))
+
+	// make room first so the terminal scrolling can't shift things underneath us
+	fmt.Print(strings.Repeat("\n", height))
+	fmt.Printf("\033[%dA", height)
+
+	fmt.Print("\0337") // save cursor
+	fmt.Print(img.escape)
+	fmt.Print("\0338") // restore cursor
+
+	for i := 0; i < height; i++ {
+		switch {
+		case top > 0 && i >= top:
+			fmt.Print(paint(boxLines[i-top]))
+		case top == 0 && i < len(boxLines):
+			fmt.Printf("\033[%dC%s", img.cols+sideGap, paint(boxLines[i]))
+		}
+		fmt.Print("\n")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// escapeKeys are the escape sequences terminals send for the keys we care
+// about. some send ESC O instead of ESC [ for the arrows, home and end
+var escapeKeys = map[string]string{
+	"\x1b[A": "up", "\x1b[B": "down", "\x1b[C": "right", "\x1b[D": "left",
+	"\x1bOA": "up", "\x1bOB": "down", "\x1bOC": "right", "\x1bOD": "left",
+	"\x1b[H": "home", "\x1b[F": "end", "\x1bOH": "home", "\x1bOF": "end",
+	"\x1b[1~": "home", "\x1b[4~": "end", "\x1b[7~": "home", "\x1b[8~": "end",
+	"\x1b[3~": "delete", "\x1b[5~": "pgup", "\x1b[6~": "pgdn",
+	"\x1b[Z": "shift-tab",
+}
+
+// controlKeys are the single byte keys that aren't text
+var controlKeys = map[byte]string{
+	1: "ctrl-a", 3: "ctrl-c", 4: "ctrl-d", 5: "ctrl-e", 9: "tab",
+	11: "ctrl-k", 12: "ctrl-l", 13: "enter", 10: "enter", 21: "ctrl-u",
+	23: "ctrl-w", 8: "backspace", 127: "backspace",
+}
+
+// readKeys reads a raw mode terminal and sends each key press on keys: a name
+// from escapeKeys/controlKeys, or the typed character itself. it returns when
+// r does, closing keys
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys splits one read into keys. a paste or a held key can put several
+// in the same read
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			key, size := parseEscape(b)
+			keys = append(keys, key)
+			b = b[size:]
+			continue
+		}
+		if name, ok := controlKeys[b[0]]; ok {
+			keys = append(keys, name)
+			b = b[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(b)
+		if r != utf8.RuneError && r >= ' ' {
+			keys = append(keys, string(r))
+		}
+		b = b[size:]
+	}
+	return keys
+}
+
+// parseEscape reads one escape sequence off the front of b. a lone ESC (or
+// one followed by something we don't know) is just "esc"
+func parseEscape(b []byte) (string, int) {
+	if len(b) < 2 || (b[1] != '[' && b[1] != 'O') {
+		return "esc", 1
+	}
+	// CSI sequences end at the first byte in @..~, after any digits and ;
+	end := 2
+	for end < len(b) && (b[end] < '@' || b[end] > '~') {
+		end++
+	}
+	if end == len(b) {
+		return "esc", 1
+	}
+	name, ok := escapeKeys[string(b[:end+1])]
+	if !ok {
+		name = "unknown"
+	}
+	return name, end + 1
+}
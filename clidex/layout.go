@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	boxChrome   = 4  // "│ " and " │" around the box contents
+	descLabel   = 6  // "Desc: "
+	maxDescWrap = 60 // any wider is hard to read
+	minStacked  = 20 // narrowest wrap when the box is under the sprite
+)
+
+// sideGap and minDescWrap come from the config (gap and wrap), set in main
+var (
+	sideGap     = 4  // columns between the sprite and the box
+	minDescWrap = 35 // what the description was always wrapped at
+)
+
+// termWidth is how many columns we get to work with, 0 when we can't tell
+// (piped output without --width). set once in main like outputColors
+var termWidth int
+
+// detectTermWidth asks the terminal, then $COLUMNS
+func detectTermWidth() int {
+	if ws, ok := terminalSize(os.Stdout); ok {
+		return int(ws.cols)
+	}
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return 0
+}
+
+// descWrap is how wide to wrap the description next to a sprite spriteCols
+// wide: as much as fits beside it, or the whole width if the box is going to
+// end up underneath anyway
+func descWrap(spriteCols int) int {
+	if termWidth <= 0 {
+		return minDescWrap
+	}
+	room := termWidth - spriteCols - sideGap - boxChrome - descLabel
+	if room < minDescWrap {
+		// the box is going under the sprite (or there is no sprite)
+		room = max(termWidth-boxChrome-descLabel, minStacked)
+	}
+	return min(room, max(maxDescWrap, minDescWrap))
+}
+
+// fitsBeside reports whether a box boxCols wide has room next to the sprite
+func fitsBeside(spriteCols, boxCols int) bool {
+	return termWidth <= 0 || spriteCols+sideGap+boxCols <= termWidth
+}
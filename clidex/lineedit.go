@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is how many lines of history get kept on disk
+const maxHistory = 1000
+
+// lineEditor reads lines from a raw mode terminal with the usual shell keys:
+// arrows, home/end, ctrl-a/e/u/k/w, up/down through history and tab completion
+type lineEditor struct {
+	prompt  string
+	keys    <-chan string // from readKeys
+	history []string
+	// complete gets the line up to the cursor and returns where the word being
+	// completed starts and what it could be
+	complete func(line string) (start int, candidates []string)
+}
+
+// readLine edits one line and returns it. io.EOF means ctrl-d on an empty line
+// (or stdin closed). ctrl-c throws the line away and starts a new one
+func (e *lineEditor) readLine() (string, error) {
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	var buf []rune
+	pos := 0
+	histPos, saved := len(e.history), ""
+	// anything typed while the last command ran is still queued up, and the
+	// terminal echoed it already. it goes into this line like it was typed
+	// now, and the redraw only happens once the queue is empty, so the prompt
+	// lands on top of the echo instead of showing it twice
+	if len(e.keys) == 0 {
+		e.redraw(buf, pos)
+	}
+
+	for key := range e.keys {
+		switch key {
+		case "enter":
+			// a line that came in all at once hasn't been drawn yet
+			e.redraw(buf, len(buf))
+			fmt.Print("\r\n")
+			return string(buf), nil
+		case "ctrl-c":
+			e.redraw(buf, len(buf))
+			fmt.Print("^C\r\n")
+			buf, pos, histPos = nil, 0, len(e.history)
+		case "ctrl-d":
+			if len(buf) == 0 {
+				fmt.Print("\r\n")
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case "backspace":
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case "delete":
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case "left":
+			pos = max(pos-1, 0)
+		case "right":
+			pos = min(pos+1, len(buf))
+		case "home", "ctrl-a":
+			pos = 0
+		case "end", "ctrl-e":
+			pos = len(buf)
+		case "ctrl-u":
+			buf, pos = buf[pos:], 0
+		case "ctrl-k":
+			buf = buf[:pos]
+		case "ctrl-w":
+			// back over any spaces, then the word before them
+			start := pos
+			for start > 0 && buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && buf[start-1] != ' ' {
+				start--
+			}
+			buf, pos = append(buf[:start], buf[pos:]...), start
+		case "ctrl-l":
+			fmt.Print("\033[H\033[2J")
+		case "up":
+			if histPos > 0 {
+				if histPos == len(e.history) {
+					saved = string(buf)
+				}
+				histPos--
+				buf = []rune(e.history[histPos])
+				pos = len(buf)
+			}
+		case "down":
+			if histPos < len(e.history) {
+				histPos++
+				line := saved
+				if histPos < len(e.history) {
+					line = e.history[histPos]
+				}
+				buf = []rune(line)
+				pos = len(buf)
+			}
+		case "tab":
+			buf, pos = e.tab(buf, pos)
+		default:
+			// anything longer is some other escape sequence
+			if len([]rune(key)) == 1 {
+				buf = append(buf[:pos], append([]rune(key), buf[pos:]...)...)
+				pos++
+			}
+		}
+		if len(e.keys) == 0 {
+			e.redraw(buf, pos)
+		}
+	}
+	return "", io.EOF
+}
+
+// redraw rewrites the prompt and line and puts the cursor back at pos
+func (e *lineEditor) redraw(buf []rune, pos int) {
+	fmt.Print("\r" + e.prompt + string(buf) + "\033[K")
+	if back := displayWidth(string(buf[pos:])); back > 0 {
+		fmt.Printf("\033[%dD", back)
+	}
+}
+
+// tab completes the word under the cursor as far as it's unambiguous, and
+// lists the choices when it can't go any further
+func (e *lineEditor) tab(buf []rune, pos int) ([]rune, int) {
+	if e.complete == nil {
+		return buf, pos
+	}
+	before := string(buf[:pos])
+	start, candidates := e.complete(before)
+	if len(candidates) == 0 {
+		return buf, pos
+	}
+
+	word := before[start:]
+	completed := candidates[0]
+	if len(candidates) == 1 {
+		completed += " "
+	} else {
+		for _, c := range candidates[1:] {
+			completed = completed[:commonPrefixLen(completed, c)]
+		}
+	}
+
+	if len(completed) <= len(word) {
+		e.listCandidates(candidates)
+		return buf, pos
+	}
+	rest := buf[pos:]
+	buf = append([]rune(before[:start]+completed), rest...)
+	return buf, pos + len([]rune(completed)) - len([]rune(word))
+}
+
+// listCandidates prints the choices under the prompt in columns, like a shell does
+func (e *lineEditor) listCandidates(candidates []string) {
+	const maxListed = 60
+	extra := 0
+	if len(candidates) > maxListed {
+		candidates, extra = candidates[:maxListed], len(candidates)-maxListed
+	}
+
+	colWidth := maxDisplayWidth(candidates) + 2
+	perRow := 1
+	if termWidth > colWidth {
+		perRow = termWidth / colWidth
+	}
+	var out strings.Builder
+	out.WriteString("\r\n")
+	for i, c := range candidates {
+		out.WriteString(c + strings.Repeat(" ", colWidth-displayWidth(c)))
+		if (i+1)%perRow == 0 || i == len(candidates)-1 {
+			out.WriteString("\r\n")
+		}
+	}
+	if extra > 0 {
+		fmt.Fprintf(&out, "...and %d more\r\n", extra)
+	}
+	fmt.Print(out.String())
+}
+
+// historyPath is $XDG_STATE_HOME/clidex/history (~/.local/state by default).
+// not the cache dir, `dex cache clear` would take it with it
+func historyPath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "clidex", "history"), nil
+}
+
+// loadHistory reads the history file, oldest first. once it's grown past
+// maxHistory the old end gets cut off and the file rewritten
+func loadHistory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+		err = os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+	}
+	return lines, err
+}
+
+// appendHistory adds one line to the end of the history file
+func appendHistory(path, line string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"clipokedex/pokeapi"
+)
+
+// levelMove is one level-up move in the game moveLines picked
+type levelMove struct {
+	level int
+	name  string
+}
+
+// moveLines lists the level-up moves from the newest game the pokemon learns
+// any in, plus a count of everything it learns some other way (TMs, eggs, tutors)
+func moveLines(moves []pokeapi.PokemonMove) []string {
+	if len(moves) == 0 {
+		return []string{"No move data"}
+	}
+
+	// version groups go up in id as the games come out
+	newest, newestID := "", 0
+	for _, m := range moves {
+		for _, d := range m.VersionGroupDetails {
+			id, ok := pokeapi.IDFromURL(d.VersionGroup.URL)
+			if ok && d.MoveLearnMethod.Name == "level-up" && id > newestID {
+				newest, newestID = d.VersionGroup.Name, id
+			}
+		}
+	}
+
+	var learned []levelMove
+	other := 0
+	for _, m := range moves {
+		byLevel := false
+		for _, d := range m.VersionGroupDetails {
+			if d.VersionGroup.Name == newest && d.MoveLearnMethod.Name == "level-up" {
+				learned = append(learned, levelMove{level: d.LevelLearnedAt, name: m.Move.Name})
+				byLevel = true
+			}
+		}
+		if !byLevel {
+			other++
+		}
+	}
+	sort.SliceStable(learned, func(i, j int) bool {
+		if learned[i].level != learned[j].level {
+			return learned[i].level < learned[j].level
+		}
+		return learned[i].name < learned[j].name
+	})
+
+	var lines []string
+	if newest != "" {
+		lines = append(lines, "Level-up moves ("+newest+"):")
+	}
+	for _, m := range learned {
+		lines = append(lines, fmt.Sprintf("Lv %3d  %s", m.level, m.name))
+	}
+	if other > 0 {
+		lines = append(lines, "", fmt.Sprintf("+%d more from TMs, eggs, tutors and older games", other))
+	}
+	return lines
+}
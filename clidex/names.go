@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// dexName is one pokemon's name as each endpoint wants it. they mostly agree,
+// but "Mr. Mime" has to become mr-mime everywhere and deoxys is deoxys-normal
+// on /pokemon but plain deoxys on /pokemon-species and in the colorscripts repo
+type dexName struct {
+	Pokemon     string // /pokemon/{name}
+	Species     string // /pokemon-species/{name}
+	Colorscript string // colorscripts/small/regular/{name}
+}
+
+// slugExceptions catches the ways people type names that the generic rules in
+// slugify can't fix, keyed by the input squashed down to just letters and digits
+var slugExceptions = map[string]string{
+	"mrmime":   "mr-mime",
+	"mimejr":   "mime-jr",
+	"mrrime":   "mr-rime",
+	"typenull": "type-null",
+	"hooh":     "ho-oh",
+	"porygonz": "porygon-z",
+	"nidoranf": "nidoran-f",
+	"nidoranm": "nidoran-m",
+	"jangmoo":  "jangmo-o",
+	"hakamoo":  "hakamo-o",
+	"kommoo":   "kommo-o",
+	"tapukoko": "tapu-koko",
+	"tapulele": "tapu-lele",
+	"tapubulu": "tapu-bulu",
+	"tapufini": "tapu-fini",
+	"wochien":  "wo-chien",
+	"chienpao": "chien-pao",
+	"tinglu":   "ting-lu",
+	"chiyu":    "chi-yu",
+}
+
+// defaultForms are species whose default pokemon has a form suffix on /pokemon.
+// asking for /pokemon/deoxys just 404s
+var defaultForms = map[string]string{
+	"deoxys":       "deoxys-normal",
+	"wormadam":     "wormadam-plant",
+	"giratina":     "giratina-altered",
+	"shaymin":      "shaymin-land",
+	"basculin":     "basculin-red-striped",
+	"darmanitan":   "darmanitan-standard",
+	"tornadus":     "tornadus-incarnate",
+	"thundurus":    "thundurus-incarnate",
+	"landorus":     "landorus-incarnate",
+	"enamorus":     "enamorus-incarnate",
+	"keldeo":       "keldeo-ordinary",
+	"meloetta":     "meloetta-aria",
+	"meowstic":     "meowstic-male",
+	"aegislash":    "aegislash-shield",
+	"pumpkaboo":    "pumpkaboo-average",
+	"gourgeist":    "gourgeist-average",
+	"zygarde":      "zygarde-50",
+	"oricorio":     "oricorio-baile",
+	"lycanroc":     "lycanroc-midday",
+	"wishiwashi":   "wishiwashi-solo",
+	"minior":       "minior-red-meteor",
+	"mimikyu":      "mimikyu-disguised",
+	"toxtricity":   "toxtricity-amped",
+	"eiscue":       "eiscue-ice",
+	"indeedee":     "indeedee-male",
+	"morpeko":      "morpeko-full-belly",
+	"urshifu":      "urshifu-single-strike",
+	"basculegion":  "basculegion-male",
+	"oinkologne":   "oinkologne-male",
+	"maushold":     "maushold-family-of-four",
+	"squawkabilly": "squawkabilly-green-plumage",
+	"palafin":      "palafin-zero",
+	"tatsugiri":    "tatsugiri-curly",
+	"dudunsparce":  "dudunsparce-two-segment",
+}
+
+// defaultFormSpecies is defaultForms backwards, deoxys-normal -> deoxys
+var defaultFormSpecies = func() map[string]string {
+	m := make(map[string]string)
+	for species, pokemon := range defaultForms {
+		m[pokemon] = species
+	}
+	return m
+}()
+
+// accentFolds turns the accented letters that show up in pokemon names into plain ones
+var accentFolds = strings.NewReplacer(
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"á", "a", "à", "a", "â", "a", "ä", "a",
+	"í", "i", "ï", "i", "ó", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ü", "u", "ñ", "n", "ç", "c",
+)
+
+// normalizeName maps whatever the user typed to the names each endpoint expects
+func normalizeName(input string) dexName {
+	slug := slugify(input)
+
+	// the default form was asked for by its form name (e.g. from the suggestion list)
+	if species, ok := defaultFormSpecies[slug]; ok {
+		return dexName{Pokemon: slug, Species: species, Colorscript: species}
+	}
+	if pokemon, ok := defaultForms[slug]; ok {
+		return dexName{Pokemon: pokemon, Species: slug, Colorscript: slug}
+	}
+	return dexName{Pokemon: slug, Species: slug, Colorscript: slug}
+}
+
+// slugify turns "Mr. Mime" into mr-mime, "Nidoran♀" into nidoran-f, "Flabébé" into flabebe etc.
+// dex numbers just pass through
+func slugify(input string) string {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = accentFolds.Replace(s)
+	s = strings.ReplaceAll(s, "♀", "-f")
+	s = strings.ReplaceAll(s, "♂", "-m")
+
+	if slug, ok := slugExceptions[squash(s)]; ok {
+		return slug
+	}
+
+	// spaces and underscores become hyphens, punctuation just goes away
+	var b strings.Builder
+	lastHyphen := true // true so we never start with a hyphen
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '_' || r == '-':
+			if !lastHyphen {
+				b.WriteRune('-')
+				lastHyphen = true
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastHyphen = false
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// squash keeps only letters and digits, so "Mr. Mime", "mr mime" and "mr_mime" all match
+func squash(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		input       string
+		pokemon     string // PokeAPI /pokemon slug
+		colorscript string // colorscripts repo slug
+	}{
+		{"pikachu", "pikachu", "pikachu"},
+		{"  Pikachu ", "pikachu", "pikachu"},
+		{"25", "25", "25"},
+		{"Mr. Mime", "mr-mime", "mr-mime"},
+		{"mr mime", "mr-mime", "mr-mime"},
+		{"Mime Jr.", "mime-jr", "mime-jr"},
+		{"Farfetch'd", "farfetchd", "farfetchd"},
+		{"Sirfetch’d", "sirfetchd", "sirfetchd"},
+		{"Nidoran♀", "nidoran-f", "nidoran-f"},
+		{"Nidoran ♂", "nidoran-m", "nidoran-m"},
+		{"Type: Null", "type-null", "type-null"},
+		{"Flabébé", "flabebe", "flabebe"},
+		{"Ho-Oh", "ho-oh", "ho-oh"},
+		{"porygon z", "porygon-z", "porygon-z"},
+		{"Porygon2", "porygon2", "porygon2"},
+		{"jangmoo", "jangmo-o", "jangmo-o"},
+		{"Tapu Koko", "tapu-koko", "tapu-koko"},
+		{"charizard_mega_x", "charizard-mega-x", "charizard-mega-x"},
+		// deoxys is deoxys-normal on /pokemon but plain deoxys everywhere else
+		{"Deoxys", "deoxys-normal", "deoxys"},
+		{"deoxys-normal", "deoxys-normal", "deoxys"},
+		{"deoxys-attack", "deoxys-attack", "deoxys-attack"},
+	}
+
+	for _, tt := range tests {
+		got := normalizeName(tt.input)
+		if got.Pokemon != tt.pokemon {
+			t.Errorf("normalizeName(%q).Pokemon = %q, want %q", tt.input, got.Pokemon, tt.pokemon)
+		}
+		if got.Colorscript != tt.colorscript {
+			t.Errorf("normalizeName(%q).Colorscript = %q, want %q", tt.input, got.Colorscript, tt.colorscript)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"clipokedex/pokeapi"
+)
+
+// recordSchemaVersion goes up whenever a field in dexRecord is removed or changes
+// meaning. adding fields doesn't bump it, so scripts should ignore unknown keys
+const recordSchemaVersion = 1
+
+// dexRecord is the stable, flattened shape we print for --output json|yaml.
+// it's deliberately NOT just DexEntry -- PokeAPI's nesting is a pain to script against
+type dexRecord struct {
+	SchemaVersion       int           `json:"schema_version"`
+	Name                string        `json:"name"`
+	ID                  int           `json:"id"`
+	Types               []string      `json:"types"`
+	Description         string        `json:"description"`
+	DescriptionLanguage string        `json:"description_language"`
+	DescriptionVersion  string        `json:"description_version"`
+	Stats               recordStats   `json:"stats"`
+	Sprites             recordSprites `json:"sprites"`
+}
+
+type recordStats struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+	Total          int `json:"total"`
+}
+
+type recordSprites struct {
+	FrontDefault string `json:"front_default"`
+	FrontShiny   string `json:"front_shiny"`
+}
+
+// newDexRecord flattens what we fetched into a dexRecord
+func newDexRecord(entry *pokeapi.DexEntry, species *pokeapi.SpeciesData, lang, game string) dexRecord {
+	rec := dexRecord{
+		SchemaVersion: recordSchemaVersion,
+		Name:          entry.Name,
+		ID:            entry.ID,
+		Types:         entryTypes(entry),
+		Sprites: recordSprites{
+			FrontDefault: entry.Sprites.FrontDefault,
+			FrontShiny:   entry.Sprites.FrontShiny,
+		},
+	}
+	if rec.Types == nil {
+		rec.Types = []string{} // [] instead of null in the json
+	}
+	if flavor := pickFlavorText(species, lang, game); flavor != nil {
+		rec.Description = cleanFlavorText(flavor.FlavorText)
+		rec.DescriptionLanguage = flavor.Language.Name
+		rec.DescriptionVersion = flavor.Version.Name
+	}
+
+	for _, s := range entry.Stats {
+		switch s.Stat.Name {
+		case "hp":
+			rec.Stats.HP = s.BaseStat
+		case "attack":
+			rec.Stats.Attack = s.BaseStat
+		case "defense":
+			rec.Stats.Defense = s.BaseStat
+		case "special-attack":
+			rec.Stats.SpecialAttack = s.BaseStat
+		case "special-defense":
+			rec.Stats.SpecialDefense = s.BaseStat
+		case "speed":
+			rec.Stats.Speed = s.BaseStat
+		}
+		rec.Stats.Total += s.BaseStat
+	}
+	return rec
+}
+
+// writeRecord prints rec as json or yaml
+func writeRecord(w io.Writer, rec dexRecord, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(rec)
+	case "yaml":
+		return writeYAML(w, reflect.ValueOf(rec), 0)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeYAML is a tiny yaml emitter, just enough for dexRecord (structs, string
+// slices, strings and ints). keys come from the json tags so both formats match.
+// strings are written json-quoted, which is valid yaml and saves us from
+// figuring out yaml's quoting rules
+func writeYAML(w io.Writer, v reflect.Value, indent int) error {
+	pad := strings.Repeat("  ", indent)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		field := v.Field(i)
+
+		switch field.Kind() {
+		case reflect.Struct:
+			fmt.Fprintf(w, "%s%s:\n", pad, key)
+			if err := writeYAML(w, field, indent+1); err != nil {
+				return err
+			}
+		case reflect.Slice:
+			if field.Len() == 0 {
+				fmt.Fprintf(w, "%s%s: []\n", pad, key)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:\n", pad, key)
+			for j := 0; j < field.Len(); j++ {
+				s, _ := json.Marshal(field.Index(j).Interface())
+				fmt.Fprintf(w, "%s  - %s\n", pad, s)
+			}
+		case reflect.String, reflect.Int:
+			s, _ := json.Marshal(field.Interface())
+			fmt.Fprintf(w, "%s%s: %s\n", pad, key, s)
+		default:
+			return fmt.Errorf("yaml: can't encode %s", field.Kind())
+		}
+	}
+	return nil
+}
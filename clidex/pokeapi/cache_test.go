@@ -0,0 +1,143 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"clipokedex/cache"
+)
+
+func TestFetchCache(t *testing.T) {
+	const url = "http://pokeapi.test/pokemon/pikachu"
+	const cachedBody, newBody = `{"id":25,"name":"old"}`, `{"id":25,"name":"new"}`
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		stale    bool             // the cached copy is past its TTL
+		offline  bool             // Client.Offline
+		noCache  bool             // nothing cached at all
+		handler  http.HandlerFunc // nil means the network is down
+		ctx      context.Context
+		want     string
+		wantErr  error
+		wantHits int
+	}{
+		{
+			name:    "fresh entry skips the network",
+			handler: func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, newBody) },
+			want:    cachedBody,
+		},
+		{
+			name:  "stale entry gets revalidated",
+			stale: true,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") != `"v1"` {
+					t.Errorf("revalidation sent If-None-Match %q", r.Header.Get("If-None-Match"))
+				}
+				w.WriteHeader(http.StatusNotModified)
+			},
+			want:     cachedBody,
+			wantHits: 1,
+		},
+		{
+			name:     "stale entry gets replaced",
+			stale:    true,
+			handler:  func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, newBody) },
+			want:     newBody,
+			wantHits: 1,
+		},
+		{
+			name:  "stale entry when revalidation can't connect",
+			stale: true,
+			want:  cachedBody,
+		},
+		{
+			name:    "stale entry when cancelled",
+			stale:   true,
+			ctx:     cancelled,
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "offline serves stale entries",
+			stale:   true,
+			offline: true,
+			want:    cachedBody,
+		},
+		{
+			name:    "offline miss",
+			offline: true,
+			noCache: true,
+			wantErr: ErrOffline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				tt.handler(w, r)
+			}))
+			defer srv.Close()
+			if tt.handler == nil {
+				// nothing listens there any more
+				srv.Close()
+			}
+
+			c := cache.New(t.TempDir(), time.Hour)
+			if !tt.noCache {
+				storedAt := time.Now()
+				if tt.stale {
+					storedAt = storedAt.Add(-2 * time.Hour)
+				}
+				if err := c.Put(&cache.Entry{URL: url, ETag: `"v1"`, StoredAt: storedAt, Body: []byte(cachedBody)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			client := NewClient()
+			client.HTTPClient = &http.Client{Transport: redirect{srv.URL}}
+			client.Cache = c
+			client.Offline = tt.offline
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			body, err := client.Fetch(ctx, url)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("body = %s, want %s", body, tt.want)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("server hit %d times, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+// redirect sends every request to the test server instead, keeping the path
+type redirect struct{ base string }
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = "http"
+	req.URL.Host = r.base[len("http://"):]
+	return http.DefaultTransport.RoundTrip(req)
+}
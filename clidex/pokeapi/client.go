@@ -0,0 +1,208 @@
+// Package pokeapi is a small client for https://pokeapi.co.
+// It used to all live inline in main() -- pulled it out so other tools
+// (and a local httptest server) can reuse it.
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"clipokedex/cache"
+)
+
+const (
+	DefaultBaseURL   = "https://pokeapi.co/api/v2"
+	DefaultUserAgent = "clidex (+https://github.com/nick-ashi/learningGo)"
+)
+
+// Client talks to PokeAPI. the zero value isn't useful, use NewClient
+// and then tweak whatever fields you need before making requests
+type Client struct {
+	// BaseURL is everything before the endpoint, no trailing slash
+	BaseURL string
+	// HTTPClient does the actual requests -- swap it out for timeouts, test transports, etc.
+	HTTPClient *http.Client
+	// UserAgent is sent on every request (PokeAPI asks nicely for one)
+	UserAgent string
+	// Cache, if set, stores every response on disk keyed by URL. fresh entries
+	// are served without touching the network, stale ones get revalidated
+	Cache *cache.Cache
+	// Offline means only ever answer from Cache, never hit the network
+	Offline bool
+}
+
+// NewClient returns a Client pointed at the public PokeAPI
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: http.DefaultClient,
+		UserAgent:  DefaultUserAgent,
+	}
+}
+
+// GetPokemon fetches /pokemon/{name}. name can also be a national dex number
+func (c *Client) GetPokemon(ctx context.Context, name string) (*DexEntry, error) {
+	var entry DexEntry
+	if err := c.getJSON(ctx, "pokemon/"+escapeName(name), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetSpecies fetches /pokemon-species/{name}
+func (c *Client) GetSpecies(ctx context.Context, name string) (*SpeciesData, error) {
+	var species SpeciesData
+	if err := c.getJSON(ctx, "pokemon-species/"+escapeName(name), &species); err != nil {
+		return nil, err
+	}
+	return &species, nil
+}
+
+// GetType fetches /type/{name}
+func (c *Client) GetType(ctx context.Context, name string) (*TypeData, error) {
+	var t TypeData
+	if err := c.getJSON(ctx, "type/"+escapeName(name), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListPokemon returns the name of every pokemon PokeAPI knows about (forms included).
+// it's one big request, so it's worth having a Cache set
+func (c *Client) ListPokemon(ctx context.Context) ([]string, error) {
+	var list NamedAPIResourceList
+	if err := c.getJSON(ctx, "pokemon?limit=100000", &list); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Results))
+	for _, r := range list.Results {
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// GetEvolutionChain fetches the chain at url, which is what
+// SpeciesData.EvolutionChain.URL points to
+func (c *Client) GetEvolutionChain(ctx context.Context, url string) (*EvolutionChain, error) {
+	var chain EvolutionChain
+	if err := c.fetchJSON(ctx, url, &chain); err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// Fetch GETs an absolute URL and returns the whole body. used for anything that
+// isn't a PokeAPI json endpoint (sprite PNGs, colorscripts, etc.)
+func (c *Client) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	var cached *cache.Entry
+	if c.Cache != nil {
+		cached, _ = c.Cache.Get(rawURL)
+	}
+
+	if c.Offline {
+		if cached == nil {
+			return nil, fmt.Errorf("%w: %s", ErrOffline, rawURL)
+		}
+		return cached.Body, nil
+	}
+	if cached != nil && c.Cache.Fresh(cached) {
+		return cached.Body, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	// stale entry -- ask the server if it changed instead of re-downloading
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		// couldn't revalidate (no network, probably) -- stale beats nothing.
+		// but not if we were cancelled or timed out, that has to get back up
+		if cached != nil && ctx.Err() == nil {
+			return cached.Body, nil
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		// still good, just reset the clock on it
+		cached.StoredAt = time.Now()
+		c.Cache.Put(cached)
+		return cached.Body, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: rawURL, StatusCode: res.StatusCode}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Cache != nil {
+		// a failed cache write shouldn't fail the lookup, we just fetch again next time
+		c.Cache.Put(&cache.Entry{
+			URL:          rawURL,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			Body:         body,
+		})
+	}
+	return body, nil
+}
+
+// getJSON fetches BaseURL/path and decodes the json body into v
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	return c.fetchJSON(ctx, c.BaseURL+"/"+path, v)
+}
+
+// fetchJSON is getJSON for absolute urls (PokeAPI hands those out in responses)
+func (c *Client) fetchJSON(ctx context.Context, rawURL string, v any) error {
+	body, err := c.Fetch(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("pokeapi: decoding %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// IDFromURL pulls the trailing id out of a resource url like
+// https://pokeapi.co/api/v2/pokemon/25/ -- handy since lists only give names and urls
+func IDFromURL(rawURL string) (int, bool) {
+	parts := strings.Split(strings.TrimSuffix(rawURL, "/"), "/")
+	id, err := strconv.Atoi(parts[len(parts)-1])
+	return id, err == nil
+}
+
+// escapeName lowercases the name and makes it safe to drop into a URL path
+func escapeName(name string) string {
+	return url.PathEscape(strings.ToLower(strings.TrimSpace(name)))
+}
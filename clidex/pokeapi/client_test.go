@@ -0,0 +1,113 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAPI serves pikachu, a 500 for "broken" and 404s for everything else,
+// and remembers the last User-Agent it saw
+func fakeAPI(t *testing.T) (*httptest.Server, *string) {
+	t.Helper()
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		switch r.URL.Path {
+		case "/api/v2/pokemon/pikachu":
+			fmt.Fprint(w, `{"name":"pikachu","id":25,"types":[{"slot":1,"type":{"name":"electric"}}]}`)
+		case "/api/v2/pokemon/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &userAgent
+}
+
+func TestGetPokemon(t *testing.T) {
+	srv, _ := fakeAPI(t)
+	client := NewClient()
+	client.BaseURL = srv.URL + "/api/v2"
+
+	tests := []struct {
+		name         string
+		wantID       int
+		wantNotFound bool
+		wantStatus   int // 0 means no *StatusError expected
+	}{
+		{name: "pikachu", wantID: 25},
+		{name: "  Pikachu ", wantID: 25},
+		{name: "missingno", wantNotFound: true, wantStatus: http.StatusNotFound},
+		{name: "broken", wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		entry, err := client.GetPokemon(context.Background(), tt.name)
+		if got := errors.Is(err, ErrNotFound); got != tt.wantNotFound {
+			t.Errorf("GetPokemon(%q): errors.Is(err, ErrNotFound) = %v, want %v (err %v)", tt.name, got, tt.wantNotFound, err)
+		}
+		var statusErr *StatusError
+		switch {
+		case tt.wantStatus != 0 && !errors.As(err, &statusErr):
+			t.Errorf("GetPokemon(%q): err = %v, want a *StatusError", tt.name, err)
+		case tt.wantStatus != 0 && statusErr.StatusCode != tt.wantStatus:
+			t.Errorf("GetPokemon(%q): status %d, want %d", tt.name, statusErr.StatusCode, tt.wantStatus)
+		case tt.wantStatus == 0 && err != nil:
+			t.Errorf("GetPokemon(%q): unexpected error %v", tt.name, err)
+		case tt.wantStatus == 0 && entry.ID != tt.wantID:
+			t.Errorf("GetPokemon(%q): id %d, want %d", tt.name, entry.ID, tt.wantID)
+		}
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	srv, userAgent := fakeAPI(t)
+
+	tests := []struct {
+		set  string // "" leaves NewClient's default
+		want string
+	}{
+		{"", DefaultUserAgent},
+		{"custom/1.0", "custom/1.0"},
+	}
+
+	for _, tt := range tests {
+		client := NewClient()
+		client.BaseURL = srv.URL + "/api/v2"
+		if tt.set != "" {
+			client.UserAgent = tt.set
+		}
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatal(err)
+		}
+		if *userAgent != tt.want {
+			t.Errorf("UserAgent %q: server saw %q, want %q", tt.set, *userAgent, tt.want)
+		}
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	srv, _ := fakeAPI(t)
+
+	tests := []struct {
+		baseURL string
+		wantErr bool
+	}{
+		{srv.URL + "/api/v2", false},
+		{srv.URL + "/api/v1", true}, // everything 404s off the real prefix
+	}
+
+	for _, tt := range tests {
+		client := NewClient()
+		client.BaseURL = tt.baseURL
+		_, err := client.GetPokemon(context.Background(), "pikachu")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("BaseURL %q: err = %v, wantErr %v", tt.baseURL, err, tt.wantErr)
+		}
+	}
+}
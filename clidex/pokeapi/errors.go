@@ -0,0 +1,29 @@
+package pokeapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNotFound is what you get back when PokeAPI 404s, e.g. a typo'd pokemon name.
+// check for it with errors.Is since the actual error is usually a *StatusError
+var ErrNotFound = errors.New("pokeapi: not found")
+
+// ErrOffline means we're in offline mode and the thing asked for isn't cached
+var ErrOffline = errors.New("pokeapi: offline and not in cache")
+
+// StatusError is returned for any response that isn't a 200
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("pokeapi: GET %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is lets errors.Is(err, ErrNotFound) match a 404 StatusError
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
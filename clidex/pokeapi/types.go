@@ -0,0 +1,153 @@
+package pokeapi
+
+type DexEntry struct {
+	Name    string        `json:"name"`
+	ID      int           `json:"id"`
+	Types   []PokemonType `json:"types"`
+	Sprites Sprites       `json:"sprites"`
+	Stats   []PokemonStat `json:"stats"`
+	Moves   []PokemonMove `json:"moves"`
+	// Species is the species this pokemon (or form) belongs to, e.g. deoxys for deoxys-attack
+	Species NamedAPIResource `json:"species"`
+}
+
+type Sprites struct {
+	SpriteSet                                      // the default in-game sprites
+	Other     map[string]SpriteSet                 `json:"other"`    // dream_world, home, official-artwork, showdown
+	Versions  map[string]map[string]VersionSprites `json:"versions"` // generation -> game -> sprites
+}
+
+// SpriteSet is the front/back/shiny/female bundle. anything the api has as null is ""
+type SpriteSet struct {
+	FrontDefault     string `json:"front_default"`
+	FrontShiny       string `json:"front_shiny"`
+	FrontFemale      string `json:"front_female"`
+	FrontShinyFemale string `json:"front_shiny_female"`
+	BackDefault      string `json:"back_default"`
+	BackShiny        string `json:"back_shiny"`
+	BackFemale       string `json:"back_female"`
+	BackShinyFemale  string `json:"back_shiny_female"`
+}
+
+// VersionSprites is one game's sprites. black/white also has animated gifs
+type VersionSprites struct {
+	SpriteSet
+	Animated *SpriteSet `json:"animated"`
+}
+
+// Go needs multiple structs for multi level json
+type PokemonType struct {
+	Type TypeName `json:"type"`
+}
+
+type TypeName struct {
+	Name string `json:"name"`
+}
+
+// PokemonStat is one base stat, Stat.Name is hp, attack, special-defense, etc.
+type PokemonStat struct {
+	BaseStat int              `json:"base_stat"`
+	Effort   int              `json:"effort"`
+	Stat     NamedAPIResource `json:"stat"`
+}
+
+// PokemonMove is a move the pokemon can learn, and how it learns it in each game
+type PokemonMove struct {
+	Move                NamedAPIResource  `json:"move"`
+	VersionGroupDetails []MoveLearnDetail `json:"version_group_details"`
+}
+
+// MoveLearnDetail is how a move is learned in one version group. LevelLearnedAt
+// only means something when MoveLearnMethod is level-up
+type MoveLearnDetail struct {
+	LevelLearnedAt  int              `json:"level_learned_at"`
+	MoveLearnMethod NamedAPIResource `json:"move_learn_method"`
+	VersionGroup    NamedAPIResource `json:"version_group"`
+}
+
+// NamedAPIResource is PokeAPI's generic {name, url} pointer to another resource
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// NamedAPIResourceList is what the list endpoints (/pokemon?limit=...) return
+type NamedAPIResourceList struct {
+	Count   int                `json:"count"`
+	Results []NamedAPIResource `json:"results"`
+}
+
+type SpeciesData struct {
+	Name              string            `json:"name"`
+	ID                int               `json:"id"`
+	FlavorTextEntries []FlavorTextEntry `json:"flavor_text_entries"`
+	EvolutionChain    APIResource       `json:"evolution_chain"`
+	Varieties         []Variety         `json:"varieties"`
+}
+
+// Variety is one of the pokemon a species comes as: raichu-alola, charizard-mega-x, etc.
+type Variety struct {
+	IsDefault bool             `json:"is_default"`
+	Pokemon   NamedAPIResource `json:"pokemon"`
+}
+
+// APIResource is like NamedAPIResource but for things that don't have a name (evolution chains)
+type APIResource struct {
+	URL string `json:"url"`
+}
+
+// EvolutionChain is the whole family tree, Chain is the first stage
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// ChainLink is one stage of an evolution chain. it's recursive --
+// EvolvesTo holds every stage this one can become (eevee has 8)
+type ChainLink struct {
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail is one way of evolving into a stage. PokeAPI leaves
+// anything that doesn't apply as null, hence all the pointers
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource  `json:"trigger"`
+	MinLevel              *int              `json:"min_level"`
+	Item                  *NamedAPIResource `json:"item"`
+	HeldItem              *NamedAPIResource `json:"held_item"`
+	KnownMove             *NamedAPIResource `json:"known_move"`
+	KnownMoveType         *NamedAPIResource `json:"known_move_type"`
+	Location              *NamedAPIResource `json:"location"`
+	TradeSpecies          *NamedAPIResource `json:"trade_species"`
+	MinHappiness          *int              `json:"min_happiness"`
+	MinAffection          *int              `json:"min_affection"`
+	MinBeauty             *int              `json:"min_beauty"`
+	TimeOfDay             string            `json:"time_of_day"`
+	Gender                *int              `json:"gender"`
+	NeedsOverworldRain    bool              `json:"needs_overworld_rain"`
+	RelativePhysicalStats *int              `json:"relative_physical_stats"`
+	TurnUpsideDown        bool              `json:"turn_upside_down"`
+}
+
+type FlavorTextEntry struct {
+	FlavorText string           `json:"flavor_text"`
+	Language   Language         `json:"language"`
+	Version    NamedAPIResource `json:"version"` // the game it's from, red, scarlet, etc.
+}
+
+type Language struct {
+	Name string `json:"name"`
+}
+
+// TypeData is /type/{name}, we only care about which pokemon have the type
+type TypeData struct {
+	Name    string        `json:"name"`
+	Pokemon []TypePokemon `json:"pokemon"`
+}
+
+type TypePokemon struct {
+	Slot    int              `json:"slot"`
+	Pokemon NamedAPIResource `json:"pokemon"`
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// nationalDexMax is the last pokemon in the national dex (pecharunt).
+// anything above it on /pokemon is an alternate form with a 10000+ id
+const nationalDexMax = 1025
+
+// defaultShinyChance is the full odds from the games
+const defaultShinyChance = 1.0 / 4096
+
+// genRanges is the national dex range each generation introduced
+var genRanges = map[int][2]int{
+	1: {1, 151},
+	2: {152, 251},
+	3: {252, 386},
+	4: {387, 493},
+	5: {494, 649},
+	6: {650, 721},
+	7: {722, 809},
+	8: {810, 905},
+	9: {906, 1025},
+}
+
+// legendaryIDs is every legendary and mythical by national dex number. hardcoded
+// since the only other way to know is fetching the species for all 1025 of them
+var legendaryIDs = []int{
+	144, 145, 146, 150, 151, // gen 1
+	243, 244, 245, 249, 250, 251, // gen 2
+	377, 378, 379, 380, 381, 382, 383, 384, 385, 386, // gen 3
+	480, 481, 482, 483, 484, 485, 486, 487, 488, 489, 490, 491, 492, 493, // gen 4
+	494, 638, 639, 640, 641, 642, 643, 644, 645, 646, 647, 648, 649, // gen 5
+	716, 717, 718, 719, 720, 721, // gen 6
+	772, 773, 785, 786, 787, 788, 789, 790, 791, 792, 800, 801, 802, 807, 808, 809, // gen 7
+	888, 889, 890, 891, 892, 893, 894, 895, 896, 897, 898, 905, // gen 8
+	1001, 1002, 1003, 1004, 1007, 1008, 1014, 1015, 1016, 1017, 1024, 1025, // gen 9
+}
+
+// randomFilter narrows down which pokemon --random/--daily can land on
+type randomFilter struct {
+	gen       int    // 0 means any
+	typeName  string // "" means any
+	legendary bool
+}
+
+// candidatePool is every national dex number matching the filter, in order
+func candidatePool(ctx context.Context, client *pokeapi.Client, f randomFilter) ([]int, error) {
+	lo, hi := 1, nationalDexMax
+	if f.gen != 0 {
+		r, ok := genRanges[f.gen]
+		if !ok {
+			return nil, fmt.Errorf("there's no generation %d (1-%d)", f.gen, len(genRanges))
+		}
+		lo, hi = r[0], r[1]
+	}
+
+	var ids []int
+	if f.typeName != "" {
+		t, err := client.GetType(ctx, f.typeName)
+		if err != nil {
+			return nil, fmt.Errorf("looking up type %q: %w", f.typeName, err)
+		}
+		for _, p := range t.Pokemon {
+			if id, ok := pokeapi.IDFromURL(p.Pokemon.URL); ok && id >= lo && id <= hi {
+				ids = append(ids, id)
+			}
+		}
+		slices.Sort(ids) // so --daily doesn't depend on the order the api lists them in
+	} else {
+		for id := lo; id <= hi; id++ {
+			ids = append(ids, id)
+		}
+	}
+
+	if f.legendary {
+		ids = slices.DeleteFunc(ids, func(id int) bool {
+			return !slices.Contains(legendaryIDs, id)
+		})
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no pokemon match those filters")
+	}
+	return ids, nil
+}
+
+// dailyRand is seeded from today's date (UTC, so the whole team flips over at
+// the same moment) plus an optional seed, so everyone gets the same pokemon
+func dailyRand(seed string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(time.Now().UTC().Format(time.DateOnly)))
+	h.Write([]byte(seed))
+	sum := h.Sum64()
+	return rand.New(rand.NewPCG(sum, sum>>32))
+}
+
+// pickPokemon picks a dex number from the pool and rolls for shiny
+func pickPokemon(rng *rand.Rand, pool []int, shinyChance float64) (id int, shiny bool) {
+	id = pool[rng.IntN(len(pool))]
+	shiny = rng.Float64() < shinyChance
+	return id, shiny
+}
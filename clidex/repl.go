@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"clipokedex/pokeapi"
+)
+
+// replOptions is what `dex repl` needs from the flags
+type replOptions struct {
+	fetch   fetchOptions
+	show    showOptions
+	timeout time.Duration // per command
+}
+
+// replCommands are the words the repl knows besides pokemon names
+var replCommands = []string{"compare", "evo", "exit", "forms", "help", "quit", "shiny", "types"}
+
+// repl is `dex repl`: one process and one client for a whole session, so the
+// http connections stay open and the pokemon list only gets read once
+type repl struct {
+	ctx    context.Context
+	client *pokeapi.Client
+	opts   replOptions
+
+	names      []string        // every pokemon, for tab completion
+	namesReady <-chan []string // names, once the list has loaded
+}
+
+// runRepl reads commands until quit or ctrl-d. ctx must not be tied to
+// Ctrl-C, each command catches that itself so it only cancels that command
+func runRepl(ctx context.Context, client *pokeapi.Client, opts replOptions) {
+	r := &repl{ctx: ctx, client: client, opts: opts}
+
+	// the list is cached after the first time, so this is usually instant
+	namesReady := make(chan []string, 1)
+	r.namesReady = namesReady
+	go func() {
+		names, _ := client.ListPokemon(ctx)
+		namesReady <- names
+	}()
+
+	// piped in commands (echo pikachu | dex repl) just get run, no editing
+	if !isTerminal(os.Stdin) {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !r.run(scanner.Text()) {
+				return
+			}
+		}
+		return
+	}
+
+	histPath, err := historyPath()
+	var history []string
+	if err == nil {
+		if history, err = loadHistory(histPath); err != nil {
+			fmt.Println("Couldn't read history:", err)
+		}
+	}
+
+	keys := make(chan string, 64)
+	go readKeys(os.Stdin, keys)
+	editor := &lineEditor{prompt: "dex> ", keys: keys, history: history, complete: r.complete}
+
+	fmt.Println(`Type a pokemon name, "help" for the rest or ctrl-d to quit`)
+	for {
+		line, err := editor.readLine()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			fmt.Println("Couldn't read the terminal:", err)
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if n := len(editor.history); n == 0 || editor.history[n-1] != line {
+			editor.history = append(editor.history, line)
+			if histPath != "" {
+				appendHistory(histPath, line)
+			}
+		}
+		if !r.run(line) {
+			return
+		}
+	}
+}
+
+// run does one command, false means quit
+func (r *repl) run(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+
+	// Ctrl-C only cancels this command, the repl keeps going
+	playCtx, stop := signal.NotifyContext(r.ctx, os.Interrupt)
+	defer stop()
+	ctx := playCtx
+	if r.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.timeout)
+		defer cancel()
+	}
+
+	// names can have spaces in them (mr mime), so everything after the command is one
+	rest := strings.Join(fields[1:], " ")
+	var restArgs []string
+	if rest != "" {
+		restArgs = []string{rest}
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "quit", "exit":
+		return false
+	case "help", "?":
+		fmt.Println("  <name or number>       look a pokemon up")
+		fmt.Println("  shiny <name>           the shiny one")
+		fmt.Println("  evo <name>             evolution chain")
+		fmt.Println("  forms <name>           regional/alternate forms")
+		fmt.Println("  compare <name> <name>  base stats side by side (mr mime vs jynx)")
+		fmt.Println("  types <type>[/<type>]  type matchups")
+		fmt.Println("  quit                   or ctrl-d")
+	case "shiny":
+		if rest == "" {
+			fmt.Println("Usage: shiny <pokemon name>")
+			break
+		}
+		r.lookup(ctx, playCtx, rest, true)
+	case "evo":
+		runEvoCmd(ctx, r.client, restArgs, r.opts.timeout)
+	case "forms":
+		runFormsCmd(ctx, r.client, restArgs, r.opts.timeout)
+	case "compare":
+		runCompareCmd(ctx, r.client, fields[1:], r.opts.timeout)
+	case "types":
+		runTypesCmd(fields[1:])
+	default:
+		r.lookup(ctx, playCtx, line, r.opts.fetch.shiny)
+	}
+	return true
+}
+
+// lookup is a plain `dex <name>`. playCtx is ctx without the timeout, for animations
+func (r *repl) lookup(ctx, playCtx context.Context, query string, shiny bool) {
+	opts := r.opts.fetch
+	opts.shiny = shiny
+	res := fetchAll(ctx, r.client, normalizeName(query), opts)
+	if res.entryErr != nil {
+		fmt.Println(fetchErrorMessage(ctx, res.entryErr, r.opts.timeout))
+		if errors.Is(res.entryErr, pokeapi.ErrNotFound) {
+			if suggestions := suggestPokemon(ctx, r.client, slugify(query)); len(suggestions) > 0 {
+				fmt.Println("Did you mean: " + strings.Join(suggestions, ", ") + "?")
+			}
+		}
+		return
+	}
+	printLookup(playCtx, res, r.opts.show)
+}
+
+// complete is the lineEditor's tab completion: commands for the first word,
+// pokemon names for everything else
+func (r *repl) complete(line string) (int, []string) {
+	if r.names == nil {
+		select {
+		case r.names = <-r.namesReady:
+		default:
+		}
+	}
+
+	start := strings.LastIndex(line, " ") + 1
+	word := strings.ToLower(line[start:])
+	var candidates []string
+	if start == 0 {
+		for _, c := range replCommands {
+			if strings.HasPrefix(c, word) {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	for _, name := range r.names {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return start, candidates
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"strings"
+)
+
+// alphaCutoff is how opaque (0-255) a pixel has to be to get drawn at all
+const alphaCutoff = 128
+
+// maxAutoCols is the widest a sprite gets rendered when --size isn't given
+const maxAutoCols = 48
+
+// pixelGrid is a sprite after trimming and resizing, ready to turn into text.
+// pixels are row-major, non-premultiplied
+type pixelGrid struct {
+	w, h int
+	px   []color.NRGBA
+}
+
+func (g *pixelGrid) at(x, y int) color.NRGBA {
+	if x < 0 || y < 0 || x >= g.w || y >= g.h {
+		return color.NRGBA{}
+	}
+	return g.px[y*g.w+x]
+}
+
+// renderOptions is how renderSprite should draw
+type renderOptions struct {
+	width  int       // columns wide, 0 keeps one column per pixel
+	mode   string    // key into cellRenderers, "" means halfblock
+	colors colorMode // only matters for dithering, paint does the rest
+	dither bool      // Floyd-Steinberg onto the 256/16 color palette
+}
+
+// cellRenderer turns a cellW x cellH block of pixels into one terminal character
+// (plus whatever color escapes it needs). cell is row-major
+type cellRenderer struct {
+	cellW, cellH int
+	// yScale squashes the sprite vertically. terminal cells are about twice as
+	// tall as wide, so a pixel is only square when cellH is twice cellW --
+	// quadrant's 2x2 pixels are twice as tall as wide and need half the rows
+	yScale float64
+	draw   func(cell []color.NRGBA) string
+}
+
+// cellRenderers are the --render modes
+var cellRenderers = map[string]cellRenderer{
+	"halfblock": {1, 2, 1, drawHalfblock},
+	"quadrant":  {2, 2, 0.5, drawQuadrant},
+	"braille":   {2, 4, 1, drawBraille},
+	"ascii":     {1, 2, 1, drawASCII},
+}
+
+// renderSprite decodes a PNG and converts it to colored terminal art.
+// by default that's ANSI truecolor escape codes and half-block characters (▀▄),
+// each terminal character representing 2 vertical pixels -- see cellRenderers
+// for the other modes
+// CAUTION: this part was largely gen'd by claude. thanks claude
+func renderSprite(data []byte, opts renderOptions) string {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "Could not decode sprite"
+	}
+
+	trimmed := trimBounds(img)
+	if trimmed.Empty() {
+		return "No sprite available"
+	}
+	return renderImage(img, trimmed, opts)
+}
+
+// renderImage is renderSprite for an already decoded image, drawing just the
+// rect part of it. animations use this directly so every frame shares one rect
+func renderImage(img image.Image, rect image.Rectangle, opts renderOptions) string {
+	renderer, ok := cellRenderers[opts.mode]
+	if !ok {
+		renderer = cellRenderers["halfblock"]
+	}
+
+	// a column is cellW pixels wide, so --size 30 in braille mode is 60 pixels.
+	// without --size only the huge ones (artwork is ~475px) get shrunk
+	targetW := rect.Dx()
+	if opts.width > 0 {
+		targetW = opts.width * renderer.cellW
+	} else if targetW > maxAutoCols*renderer.cellW {
+		targetW = maxAutoCols * renderer.cellW
+	}
+	grid := downsample(img, rect, targetW, renderer.yScale)
+	if opts.dither {
+		ditherGrid(grid, opts.colors)
+	}
+
+	var result strings.Builder
+	cell := make([]color.NRGBA, renderer.cellW*renderer.cellH)
+
+	for y := 0; y < grid.h; y += renderer.cellH {
+		for x := 0; x < grid.w; x += renderer.cellW {
+			// pixels past the edge come back transparent from at()
+			for cy := 0; cy < renderer.cellH; cy++ {
+				for cx := 0; cx < renderer.cellW; cx++ {
+					cell[cy*renderer.cellW+cx] = grid.at(x+cx, y+cy)
+				}
+			}
+			result.WriteString(renderer.draw(cell))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// drawHalfblock draws a top and bottom pixel with ▀/▄ and fg/bg colors
+func drawHalfblock(cell []color.NRGBA) string {
+	top, bot := cell[0], cell[1]
+	topTransparent := top.A < alphaCutoff
+	botTransparent := bot.A < alphaCutoff
+
+	if topTransparent && botTransparent {
+		return " "
+	} else if topTransparent {
+		// only bottom pixel visible — use ▄ with foreground color
+		return fgEscape(bot) + "▄\033[0m"
+	} else if botTransparent {
+		// only top pixel visible — use ▀ with foreground color
+		return fgEscape(top) + "▀\033[0m"
+	}
+	// both pixels visible — ▀ foreground=top, background=bottom
+	return fgEscape(top) + bgEscape(bot) + "▀\033[0m"
+}
+
+// quadrantGlyphs is indexed by which quarters are filled:
+// 1 top left, 2 top right, 4 bottom left, 8 bottom right
+var quadrantGlyphs = []string{
+	" ", "▘", "▝", "▀", "▖", "▌", "▞", "▛",
+	"▗", "▚", "▐", "▜", "▄", "▙", "▟", "█",
+}
+
+// drawQuadrant draws a 2x2 block with the quadrant characters. a cell only
+// gets two colors (fg and bg), so the four pixels are split into two groups
+// around the two most different ones and each group gets its average color
+func drawQuadrant(cell []color.NRGBA) string {
+	var opaque []int
+	for i, p := range cell {
+		if p.A >= alphaCutoff {
+			opaque = append(opaque, i)
+		}
+	}
+	if len(opaque) == 0 {
+		return " "
+	}
+
+	// any transparency means the background has to stay the terminal's, so
+	// every visible pixel shares the foreground color
+	if len(opaque) < len(cell) {
+		mask := 0
+		for _, i := range opaque {
+			mask |= 1 << i
+		}
+		return fgEscape(averageColor(cell, opaque)) + quadrantGlyphs[mask] + "\033[0m"
+	}
+
+	// all four visible: seed two groups with the pair that differs the most
+	seedA, seedB, far := 0, 0, -1
+	for i := range cell {
+		for j := i + 1; j < len(cell); j++ {
+			if d := colorDistance(cell[i], cell[j]); d > far {
+				seedA, seedB, far = i, j, d
+			}
+		}
+	}
+	var groupA, groupB []int
+	mask := 0
+	for i := range cell {
+		if colorDistance(cell[i], cell[seedA]) <= colorDistance(cell[i], cell[seedB]) {
+			groupA = append(groupA, i)
+			mask |= 1 << i
+		} else {
+			groupB = append(groupB, i)
+		}
+	}
+	if len(groupB) == 0 {
+		return fgEscape(averageColor(cell, groupA)) + "█\033[0m"
+	}
+	return fgEscape(averageColor(cell, groupA)) + bgEscape(averageColor(cell, groupB)) + quadrantGlyphs[mask] + "\033[0m"
+}
+
+// brailleBits is the dot bit for each pixel of a 2x4 cell, row-major.
+// braille numbers its dots down the left column first, then the right,
+// and dots 7/8 got bolted onto the bottom later, hence the odd order
+var brailleBits = []rune{
+	0x01, 0x08,
+	0x02, 0x10,
+	0x04, 0x20,
+	0x40, 0x80,
+}
+
+// drawBraille draws a 2x4 block as braille dots, one dot per visible pixel.
+// only one color per cell, so it's the average of the visible pixels
+func drawBraille(cell []color.NRGBA) string {
+	var dots rune
+	var opaque []int
+	for i, p := range cell {
+		if p.A >= alphaCutoff {
+			dots |= brailleBits[i]
+			opaque = append(opaque, i)
+		}
+	}
+	if dots == 0 {
+		return " "
+	}
+	return fgEscape(averageColor(cell, opaque)) + string(0x2800+dots) + "\033[0m"
+}
+
+// asciiRamp goes from dim to dense, picked by brightness
+const asciiRamp = ".:-=+*#%@"
+
+// drawASCII draws a top/bottom pixel pair as one character from asciiRamp,
+// for terminals without unicode fonts
+func drawASCII(cell []color.NRGBA) string {
+	var opaque []int
+	for i, p := range cell {
+		if p.A >= alphaCutoff {
+			opaque = append(opaque, i)
+		}
+	}
+	if len(opaque) == 0 {
+		return " "
+	}
+
+	c := averageColor(cell, opaque)
+	// rec. 601 luma, good enough for picking a character
+	luma := (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+	ch := asciiRamp[luma*(len(asciiRamp)-1)/255]
+	return fgEscape(c) + string(ch) + "\033[0m"
+}
+
+// averageColor averages the cell pixels at the given indexes
+func averageColor(cell []color.NRGBA, idx []int) color.NRGBA {
+	var r, g, b int
+	for _, i := range idx {
+		r += int(cell[i].R)
+		g += int(cell[i].G)
+		b += int(cell[i].B)
+	}
+	n := len(idx)
+	return color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}
+}
+
+func fgEscape(c color.NRGBA) string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+func bgEscape(c color.NRGBA) string {
+	return fmt.Sprintf("\033[48;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// trimBounds finds the bounding box of non-transparent pixels so we don't
+// print a bunch of empty space around the pokemon
+func trimBounds(img image.Image) image.Rectangle {
+	bounds := img.Bounds()
+	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X, bounds.Min.Y
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA).A >= alphaCutoff {
+				minX = min(minX, x)
+				maxX = max(maxX, x)
+				minY = min(minY, y)
+				maxY = max(maxY, y)
+			}
+		}
+	}
+	if minX > maxX {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
+
+// downsample resizes the rect part of img to targetW pixels wide (keeping the
+// aspect ratio, times yScale for the height) with a box filter: every output pixel is the area-weighted
+// average of all the source pixels it covers, instead of just grabbing one of
+// them like the old skip-every-n-pixels loop did. colors are averaged
+// premultiplied by alpha so transparent pixels don't drag the edges toward black
+func downsample(img image.Image, rect image.Rectangle, targetW int, yScale float64) *pixelGrid {
+	srcW, srcH := rect.Dx(), rect.Dy()
+	if targetW < 1 {
+		targetW = 1
+	}
+	scale := float64(srcW) / float64(targetW) // source pixels per output pixel
+	scaleY := scale / yScale
+	targetH := max(1, int(float64(srcH)/scaleY+0.5))
+
+	// grab everything as NRGBA once, img.At in the inner loop is slow
+	src := make([]color.NRGBA, srcW*srcH)
+	for y := 0; y < srcH; y++ {
+		for x := 0; x < srcW; x++ {
+			src[y*srcW+x] = color.NRGBAModel.Convert(img.At(rect.Min.X+x, rect.Min.Y+y)).(color.NRGBA)
+		}
+	}
+
+	grid := &pixelGrid{w: targetW, h: targetH, px: make([]color.NRGBA, targetW*targetH)}
+	for dy := 0; dy < targetH; dy++ {
+		y0, y1 := float64(dy)*scaleY, min(float64(dy+1)*scaleY, float64(srcH))
+		for dx := 0; dx < targetW; dx++ {
+			x0, x1 := float64(dx)*scale, min(float64(dx+1)*scale, float64(srcW))
+
+			var r, g, b, a, area float64
+			for sy := int(y0); float64(sy) < y1; sy++ {
+				// how much of this source row falls inside the output pixel
+				wy := min(y1, float64(sy+1)) - max(y0, float64(sy))
+				for sx := int(x0); float64(sx) < x1; sx++ {
+					wx := min(x1, float64(sx+1)) - max(x0, float64(sx))
+					w := wx * wy
+					p := src[sy*srcW+sx]
+					pa := float64(p.A) * w
+					r += float64(p.R) * pa
+					g += float64(p.G) * pa
+					b += float64(p.B) * pa
+					a += pa
+					area += w
+				}
+			}
+
+			var out color.NRGBA
+			if a > 0 {
+				out = color.NRGBA{
+					R: uint8(r/a + 0.5),
+					G: uint8(g/a + 0.5),
+					B: uint8(b/a + 0.5),
+					A: uint8(a/area + 0.5),
+				}
+			}
+			grid.px[dy*targetW+dx] = out
+		}
+	}
+	return grid
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"clipokedex/pokeapi"
+)
+
+// maxBaseStat is the highest base stat in the games (blissey's hp) -- bars are scaled to it
+const maxBaseStat = 255
+
+// statOrder is the order the games show stats in, with the short labels we print
+var statOrder = []struct {
+	name  string
+	label string
+}{
+	{"hp", "HP"},
+	{"attack", "Atk"},
+	{"defense", "Def"},
+	{"special-attack", "SpA"},
+	{"special-defense", "SpD"},
+	{"speed", "Spe"},
+}
+
+// statLines renders the base stats as colored bars that fit in width columns:
+// "Atk  55 ██████░░░░░░". the last line is the total
+func statLines(stats []pokeapi.PokemonStat, width int) []string {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]int)
+	for _, s := range stats {
+		byName[s.Stat.Name] = s.BaseStat
+	}
+
+	// "Atk 255 " is 8 columns, the bar gets whatever's left
+	barWidth := width - 8
+	if barWidth < 10 {
+		barWidth = 10
+	}
+
+	var lines []string
+	total := 0
+	for _, st := range statOrder {
+		value := byName[st.name]
+		total += value
+
+		filled := value * barWidth / maxBaseStat
+		if filled == 0 && value > 0 {
+			filled = 1 // always show a sliver for nonzero stats
+		}
+		g := currentTheme.border
+		bar := statColor(value) + strings.Repeat(g.barFull, filled) + "\033[0m" +
+			"\033[90m" + strings.Repeat(g.barEmpty, barWidth-filled) + "\033[0m"
+		lines = append(lines, fmt.Sprintf("%-3s %3d %s", st.label, value, bar))
+	}
+	lines = append(lines, fmt.Sprintf("%-3s %3d", "Tot", total))
+
+	return lines
+}
+
+// statColor picks a bar color by how good the stat is, roughly like showdown does
+func statColor(value int) string {
+	switch {
+	case value < 50:
+		return "\033[38;2;243;68;68m" // red
+	case value < 80:
+		return "\033[38;2;255;127;15m" // orange
+	case value < 100:
+		return "\033[38;2;255;221;87m" // yellow
+	case value < 120:
+		return "\033[38;2;160;229;21m" // green
+	default:
+		return "\033[38;2;35;205;94m" // dark green
+	}
+}
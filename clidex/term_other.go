@@ -0,0 +1,27 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// winsize is struct winsize from <sys/ioctl.h>
+type winsize struct {
+	rows, cols     uint16
+	xpixel, ypixel uint16
+}
+
+// terminalSize isn't implemented without TIOCGWINSZ, callers fall back to defaults
+func terminalSize(f *os.File) (winsize, bool) {
+	return winsize{}, false
+}
+
+// makeRaw needs termios, which this platform doesn't have
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode isn't supported on this platform")
+}
+
+// notifyResize does nothing without SIGWINCH
+func notifyResize(ch chan<- os.Signal) {}
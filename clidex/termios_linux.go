@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// the termios ioctls are called TCGETS/TCSETS on linux
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
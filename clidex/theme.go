@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// borderStyle is the glyphs a box is drawn with, plus the ones inside it
+type borderStyle struct {
+	topLeft, topRight, bottomLeft, bottomRight string
+	horizontal, vertical                       string
+	innerGlyphs
+}
+
+// innerGlyphs are the stat bars and the evolution tree
+type innerGlyphs struct {
+	barFull, barEmpty          string
+	branch, lastBranch, indent string // indent goes under a branch that has more after it
+}
+
+var (
+	unicodeInner = innerGlyphs{"█", "░", "├── ", "└── ", "│   "}
+	asciiInner   = innerGlyphs{"#", "-", "|-- ", "`-- ", "|   "}
+)
+
+// borderStyles are the --theme options. ascii is for terminals without box
+// drawing fonts, none keeps the spacing but draws nothing
+var borderStyles = map[string]borderStyle{
+	"rounded": {"╭", "╮", "╰", "╯", "─", "│", unicodeInner},
+	"square":  {"┌", "┐", "└", "┘", "─", "│", unicodeInner},
+	"double":  {"╔", "╗", "╚", "╝", "═", "║", unicodeInner},
+	"ascii":   {"+", "+", "+", "+", "-", "|", asciiInner},
+	"none":    {" ", " ", " ", " ", " ", " ", unicodeInner},
+}
+
+// typeColors are the usual colors for each type, the box border and headings
+// get the pokemon's primary one
+var typeColors = map[string]color.NRGBA{
+	"normal":   {0xA8, 0xA7, 0x7A, 0xFF},
+	"fire":     {0xEE, 0x81, 0x30, 0xFF},
+	"water":    {0x63, 0x90, 0xF0, 0xFF},
+	"electric": {0xF7, 0xD0, 0x2C, 0xFF},
+	"grass":    {0x7A, 0xC7, 0x4C, 0xFF},
+	"ice":      {0x96, 0xD9, 0xD6, 0xFF},
+	"fighting": {0xC2, 0x2E, 0x28, 0xFF},
+	"poison":   {0xA3, 0x3E, 0xA1, 0xFF},
+	"ground":   {0xE2, 0xBF, 0x65, 0xFF},
+	"flying":   {0xA9, 0x8F, 0xF3, 0xFF},
+	"psychic":  {0xF9, 0x55, 0x87, 0xFF},
+	"bug":      {0xA6, 0xB9, 0x1A, 0xFF},
+	"rock":     {0xB6, 0xA1, 0x36, 0xFF},
+	"ghost":    {0x73, 0x57, 0x97, 0xFF},
+	"dragon":   {0x6F, 0x35, 0xFC, 0xFF},
+	"dark":     {0x70, 0x57, 0x46, 0xFF},
+	"steel":    {0xB7, 0xB7, 0xCE, 0xFF},
+	"fairy":    {0xD6, 0x85, 0xAD, 0xFF},
+}
+
+// plainAccent is the bright white the box always used to be
+const plainAccent = "\033[97m"
+
+// theme is how info boxes get drawn. there's one, set in main like outputColors
+type theme struct {
+	border     borderStyle
+	typeColors bool
+	colors     map[string]color.NRGBA // typeColors plus whatever the theme file changed
+}
+
+var currentTheme = theme{border: borderStyles["rounded"], typeColors: true, colors: typeColors}
+
+// themeFile is theme.json in the config dir. everything is optional:
+//
+//	{"border": "double", "type_colors": true, "colors": {"fire": "#ff4400"}}
+type themeFile struct {
+	Border     string            `json:"border"`
+	TypeColors *bool             `json:"type_colors"`
+	Colors     map[string]string `json:"colors"`
+}
+
+// themePath is $XDG_CONFIG_HOME/clidex/theme.json
+func themePath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "clidex", "theme.json"), nil
+}
+
+// loadTheme reads the user's theme file on top of the defaults. no file is fine
+func loadTheme(path string) (theme, error) {
+	t := currentTheme
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return t, err
+	}
+
+	var file themeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return t, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Border != "" {
+		if err := t.setBorder(file.Border); err != nil {
+			return t, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if file.TypeColors != nil {
+		t.typeColors = *file.TypeColors
+	}
+	if len(file.Colors) > 0 {
+		t.colors = make(map[string]color.NRGBA)
+		for name, c := range typeColors {
+			t.colors[name] = c
+		}
+		for name, hex := range file.Colors {
+			c, err := parseHexColor(hex)
+			if err != nil {
+				return t, fmt.Errorf("%s: %s: %w", path, name, err)
+			}
+			t.colors[strings.ToLower(name)] = c
+		}
+	}
+	return t, nil
+}
+
+// setBorder switches the theme to one of borderStyles
+func (t *theme) setBorder(name string) error {
+	style, ok := borderStyles[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, pick one of %s", name, strings.Join(borderNames(), ", "))
+	}
+	t.border = style
+	return nil
+}
+
+func borderNames() []string {
+	var names []string
+	for name := range borderStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// accent is the escape for a pokemon's box border and headings: its first
+// type's color, or plain white
+func (t theme) accent(types []string) string {
+	if !t.typeColors || len(types) == 0 {
+		return plainAccent
+	}
+	c, ok := t.colors[types[0]]
+	if !ok {
+		return plainAccent
+	}
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// parseHexColor reads #rrggbb (the # is optional)
+func parseHexColor(s string) (color.NRGBA, error) {
+	var c color.NRGBA
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return c, fmt.Errorf("bad color %q, want #rrggbb", s)
+	}
+	if _, err := fmt.Sscanf(s, "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("bad color %q, want #rrggbb", s)
+	}
+	c.A = 0xFF
+	return c, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"clipokedex/pokeapi"
+)
+
+// allTypes is every type in the order the chart below uses
+var allTypes = []string{
+	"normal", "fire", "water", "electric", "grass", "ice",
+	"fighting", "poison", "ground", "flying", "psychic", "bug",
+	"rock", "ghost", "dragon", "dark", "steel", "fairy",
+}
+
+// typeChart[attacker][defender] is the damage multiplier, gen 6+ rules.
+// baked in so matchups never need the network
+var typeChart = func() [18][18]float64 {
+	const h = 0.5 // just so the table stays readable
+	return [18][18]float64{
+		// columns are the defending types, same order as the rows
+		/* NOR */ {1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, h, 0, 1, 1, h, 1},
+		/* FIR */ {1, h, h, 1, 2, 2, 1, 1, 1, 1, 1, 2, h, 1, h, 1, 2, 1},
+		/* WAT */ {1, 2, h, 1, h, 1, 1, 1, 2, 1, 1, 1, 2, 1, h, 1, 1, 1},
+		/* ELE */ {1, 1, 2, h, h, 1, 1, 1, 0, 2, 1, 1, 1, 1, h, 1, 1, 1},
+		/* GRA */ {1, h, 2, 1, h, 1, 1, h, 2, h, 1, h, 2, 1, h, 1, h, 1},
+		/* ICE */ {1, h, h, 1, 2, h, 1, 1, 2, 2, 1, 1, 1, 1, 2, 1, h, 1},
+		/* FIG */ {2, 1, 1, 1, 1, 2, 1, h, 1, h, h, h, 2, 0, 1, 2, 2, h},
+		/* POI */ {1, 1, 1, 1, 2, 1, 1, h, h, 1, 1, 1, h, h, 1, 1, 0, 2},
+		/* GRO */ {1, 2, 1, 2, h, 1, 1, 2, 1, 0, 1, h, 2, 1, 1, 1, 2, 1},
+		/* FLY */ {1, 1, 1, h, 2, 1, 2, 1, 1, 1, 1, 2, h, 1, 1, 1, h, 1},
+		/* PSY */ {1, 1, 1, 1, 1, 1, 2, 2, 1, 1, h, 1, 1, 1, 1, 0, h, 1},
+		/* BUG */ {1, h, 1, 1, 2, 1, h, h, 1, h, 2, 1, 1, h, 1, 2, h, h},
+		/* ROC */ {1, 2, 1, 1, 1, 2, h, 1, h, 2, 1, 2, 1, 1, 1, 1, h, 1},
+		/* GHO */ {0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, 1, 2, 1, h, 1, 1},
+		/* DRA */ {1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, h, 0},
+		/* DAR */ {1, 1, 1, 1, 1, 1, h, 1, 1, 1, 2, 1, 1, 2, 1, h, 1, h},
+		/* STE */ {1, h, h, h, 1, 2, 1, 1, 1, 1, 1, 1, 2, 1, 1, 1, h, 2},
+		/* FAI */ {1, h, 1, 1, 1, 1, 2, h, 1, 1, 1, 1, 1, 1, 2, 2, h, 1},
+	}
+}()
+
+// typeIndex maps "fire" -> 1 etc.
+var typeIndex = func() map[string]int {
+	m := make(map[string]int)
+	for i, t := range allTypes {
+		m[t] = i
+	}
+	return m
+}()
+
+// matchupBuckets are the multipliers worth showing, with their labels.
+// 1x is left out since that's most of the chart
+var matchupBuckets = []struct {
+	mult  float64
+	label string
+}{
+	{4, "4x"},
+	{2, "2x"},
+	{0.5, "1/2x"},
+	{0.25, "1/4x"},
+	{0, "0x"},
+}
+
+// defensiveProfile works out how hard every attacking type hits the given
+// defending type combo, grouped by multiplier (4 -> [rock], 2 -> [...], ...)
+func defensiveProfile(defTypes []string) (map[float64][]string, error) {
+	var defIdx []int
+	for _, t := range defTypes {
+		i, ok := typeIndex[strings.ToLower(t)]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+		defIdx = append(defIdx, i)
+	}
+
+	profile := make(map[float64][]string)
+	for atk, atkName := range allTypes {
+		mult := 1.0
+		for _, def := range defIdx {
+			mult *= typeChart[atk][def]
+		}
+		if mult != 1 {
+			profile[mult] = append(profile[mult], atkName)
+		}
+	}
+	return profile, nil
+}
+
+// entryTypes pulls the plain type names out of a DexEntry
+func entryTypes(entry *pokeapi.DexEntry) []string {
+	var types []string
+	for _, t := range entry.Types {
+		types = append(types, t.Type.Name)
+	}
+	return types
+}
+
+// matchupLines renders a profile as "2x:   WATER, ROCK" lines wrapped to width
+func matchupLines(profile map[float64][]string, width int) []string {
+	var lines []string
+	for _, b := range matchupBuckets {
+		names := profile[b.mult]
+		if len(names) == 0 {
+			continue
+		}
+		label := fmt.Sprintf("%-6s", b.label+":")
+		wrapped := wordWrap(strings.ToUpper(strings.Join(names, ", ")), width-len(label))
+		for j, line := range wrapped {
+			if j == 0 {
+				lines = append(lines, label+line)
+			} else {
+				lines = append(lines, strings.Repeat(" ", len(label))+line)
+			}
+		}
+	}
+	return lines
+}
+
+// runTypesCmd handles `dex types fire/flying` -- no network needed
+func runTypesCmd(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: dex types <type>[/<type>]")
+		fmt.Println("Types:", strings.Join(allTypes, ", "))
+		return
+	}
+
+	// accept fire/flying, fire,flying or fire flying
+	var defTypes []string
+	for _, arg := range args {
+		defTypes = append(defTypes, strings.FieldsFunc(arg, func(r rune) bool {
+			return r == '/' || r == ','
+		})...)
+	}
+
+	profile, err := defensiveProfile(defTypes)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Types:", strings.Join(allTypes, ", "))
+		return
+	}
+
+	fmt.Println("Defending as", strings.ToUpper(strings.Join(defTypes, "/")))
+	for _, line := range matchupLines(profile, 60) {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"clipokedex/pokeapi"
+)
+
+// spriteVariant is which image --back, --female, --artwork and --game asked for
+type spriteVariant struct {
+	back    bool
+	female  bool
+	artwork bool
+	game    string // a version group like red-blue, or part of one like "crystal"
+}
+
+// plain means the default front sprite, the only one colorscripts has
+func (v spriteVariant) plain() bool {
+	return !v.back && !v.female && !v.artwork && v.game == ""
+}
+
+// spriteSets is where to look for the variant, best first. the default in-game
+// sprites always come last so there's something to show. v.game has to be
+// one gameSprites finds, fetchSprite drops it when it isn't
+func spriteSets(sprites pokeapi.Sprites, v spriteVariant) []pokeapi.SpriteSet {
+	var sets []pokeapi.SpriteSet
+	if set, ok := gameSprites(sprites, v.game); ok {
+		sets = append(sets, set)
+	}
+	if v.artwork {
+		// home has the same kind of big render but with shinies and females
+		for _, key := range []string{"official-artwork", "home"} {
+			if set, ok := sprites.Other[key]; ok {
+				sets = append(sets, set)
+			}
+		}
+	}
+	return append(sets, sprites.SpriteSet)
+}
+
+// gameSprites finds a game's sprites, see matchGame for what counts
+func gameSprites(sprites pokeapi.Sprites, game string) (pokeapi.SpriteSet, bool) {
+	if game == "" {
+		return pokeapi.SpriteSet{}, false
+	}
+	name, ok := matchGame(spriteGames(sprites), game)
+	return gameSets(sprites)[name], ok
+}
+
+// matchGame picks game out of names: the exact name first, then a name that's
+// part of game (--game=red-blue gets the red description), then one game is
+// part of (--game=red gets the red-blue sprites, --game=lets-go gets
+// lets-go-pikachu). parts are whole words between dashes, so "e" or a typo
+// doesn't match half the list
+func matchGame(names []string, game string) (string, bool) {
+	game = slugify(game)
+	within := func(part, whole string) bool {
+		return strings.Contains("-"+whole+"-", "-"+part+"-")
+	}
+	matches := []func(name string) bool{
+		func(name string) bool { return name == game },
+		func(name string) bool { return within(name, game) },
+		func(name string) bool { return within(game, name) },
+	}
+	for _, match := range matches {
+		for _, name := range names {
+			if match(name) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// gameError is --game naming something this pokemon has neither sprites nor
+// a description from
+type gameError struct {
+	Pokemon string
+	Game    string
+	Have    []string // every game it does have something from
+}
+
+func (e *gameError) Error() string {
+	return fmt.Sprintf("%s has nothing from %s", e.Pokemon, e.Game)
+}
+
+// checkGame makes sure --game picks out a sprite set or a description, so a
+// typo doesn't quietly get the defaults. species is nil when it wasn't fetched,
+// then only the sprites count
+func checkGame(entry *pokeapi.DexEntry, species *pokeapi.SpeciesData, game string) error {
+	if game == "" {
+		return nil
+	}
+	have := spriteGames(entry.Sprites)
+	if species != nil {
+		have = append(have, flavorVersions(species)...)
+	}
+	if _, ok := matchGame(have, game); ok {
+		return nil
+	}
+	sort.Strings(have)
+	return &gameError{Pokemon: entry.Name, Game: game, Have: slices.Compact(have)}
+}
+
+// gameSets flattens versions (generation -> game -> sprites) down to game -> sprites,
+// keeping only games that have at least a front sprite for this pokemon
+func gameSets(sprites pokeapi.Sprites) map[string]pokeapi.SpriteSet {
+	sets := make(map[string]pokeapi.SpriteSet)
+	for _, games := range sprites.Versions {
+		for name, set := range games {
+			// icons are the tiny menu sprites, not a game
+			if name != "icons" && set.FrontDefault != "" {
+				sets[name] = set.SpriteSet
+			}
+		}
+	}
+	return sets
+}
+
+// spriteGames is the sorted game names from gameSets
+func spriteGames(sprites pokeapi.Sprites) []string {
+	var names []string
+	for name := range gameSets(sprites) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pickSpriteURL goes through the sets in order and returns the first url that
+// exists. inside a set it gives up on female first (most pokemon don't have
+// one), then shiny (old games don't), then back
+func pickSpriteURL(sets []pokeapi.SpriteSet, shiny bool, v spriteVariant) string {
+	for _, set := range sets {
+		for _, back := range fallbacks(v.back) {
+			for _, sh := range fallbacks(shiny) {
+				for _, female := range fallbacks(v.female) {
+					if url := spriteField(set, back, sh, female); url != "" {
+						return url
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// fallbacks is want, then false if want was true
+func fallbacks(want bool) []bool {
+	if want {
+		return []bool{true, false}
+	}
+	return []bool{false}
+}
+
+func spriteField(set pokeapi.SpriteSet, back, shiny, female bool) string {
+	switch {
+	case back && shiny && female:
+		return set.BackShinyFemale
+	case back && shiny:
+		return set.BackShiny
+	case back && female:
+		return set.BackFemale
+	case back:
+		return set.BackDefault
+	case shiny && female:
+		return set.FrontShinyFemale
+	case shiny:
+		return set.FrontShiny
+	case female:
+		return set.FrontFemale
+	default:
+		return set.FrontDefault
+	}
+}
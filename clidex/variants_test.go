@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"clipokedex/pokeapi"
+)
+
+func TestMatchGame(t *testing.T) {
+	names := []string{"red-blue", "yellow", "crystal", "lets-go-pikachu", "red", "scarlet"}
+
+	tests := []struct {
+		game   string
+		want   string
+		wantOK bool
+	}{
+		{"scarlet", "scarlet", true},
+		{"Crystal", "crystal", true},
+		{"red", "red", true},       // exact beats red-blue
+		{"blue", "red-blue", true}, // part of a version group
+		{"lets-go", "lets-go-pikachu", true},
+		{"yellow-x", "yellow", true}, // the game is part of the group asked for
+		{"crystl", "", false},
+		{"e", "", false}, // only whole words, not any name with an e in it
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := matchGame(names, tt.game)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("matchGame(%q) = %q, %v, want %q, %v", tt.game, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckGame(t *testing.T) {
+	entry := &pokeapi.DexEntry{Name: "pikachu", Sprites: pokeapi.Sprites{
+		Versions: map[string]map[string]pokeapi.VersionSprites{
+			"generation-i": {
+				"red-blue": {SpriteSet: pokeapi.SpriteSet{FrontDefault: "rb.png"}},
+				"yellow":   {}, // no sprite, doesn't count
+			},
+		},
+	}}
+	species := &pokeapi.SpeciesData{FlavorTextEntries: []pokeapi.FlavorTextEntry{
+		{Language: pokeapi.Language{Name: "en"}, Version: pokeapi.NamedAPIResource{Name: "red"}},
+		{Language: pokeapi.Language{Name: "ja"}, Version: pokeapi.NamedAPIResource{Name: "scarlet"}},
+	}}
+
+	tests := []struct {
+		game     string
+		species  *pokeapi.SpeciesData
+		wantHave []string // nil means no error
+	}{
+		{"", species, nil},
+		{"red-blue", species, nil},
+		{"scarlet", species, nil}, // description only, in any language
+		{"yellow", species, []string{"red", "red-blue", "scarlet"}},
+		{"crystl", species, []string{"red", "red-blue", "scarlet"}},
+		{"scarlet", nil, []string{"red-blue"}}, // no species, only sprites count
+	}
+
+	for _, tt := range tests {
+		err := checkGame(entry, tt.species, tt.game)
+		var gameErr *gameError
+		switch {
+		case tt.wantHave == nil && err != nil:
+			t.Errorf("checkGame(%q): unexpected error %v", tt.game, err)
+		case tt.wantHave != nil && !errors.As(err, &gameErr):
+			t.Errorf("checkGame(%q): err = %v, want a *gameError", tt.game, err)
+		case tt.wantHave != nil && !slices.Equal(gameErr.Have, tt.wantHave):
+			t.Errorf("checkGame(%q): have %q, want %q", tt.game, gameErr.Have, tt.wantHave)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges are the East Asian Wide/Fullwidth blocks (and the emoji that
+// default to emoji presentation) that take two terminal columns.
+// sorted so runeWidth can binary search it
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18AFF}, {0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F202}, {0x1F210, 0x1F23B},
+	{0x1F240, 0x1F248}, {0x1F250, 0x1F251}, {0x1F260, 0x1F265}, {0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567}, {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7}, {0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC},
+	{0x1F7E0, 0x1F7EB}, {0x1F7F0, 0x1F7F0}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f' // asks for the 2 column emoji version of the rune before it
+)
+
+// runeWidth is how many columns one rune takes on its own: 0 for combining
+// marks and other invisible stuff, 2 for wide/fullwidth, 1 for everything else
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.IsControl(r):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF: // hangul vowels/finals that stack onto the syllable
+		return 0
+	case r < 0x1100:
+		return 1
+	}
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+	return 1
+}
+
+// extendsCluster reports whether r sticks to the grapheme before it instead
+// of starting its own: accents, ZWJ, variation selectors, skin tones
+func extendsCluster(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) ||
+		(r >= 0x1160 && r <= 0x11FF)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// graphemes splits s into what a terminal draws as one character: "é" as
+// e + combining accent, 👩‍👩‍👧 joined with ZWJs and 🇯🇵 as two regional
+// indicators are all one. it's a cut down UAX #29, good enough for names and
+// flavor text
+func graphemes(s string) []string {
+	var clusters []string
+	start := 0
+	var prev rune
+	regional := 0 // regional indicators in the current cluster
+	for i, r := range s {
+		joins := i > 0 && (extendsCluster(r) || prev == zeroWidthJoiner ||
+			(isRegionalIndicator(r) && regional == 1))
+		if i > 0 && !joins {
+			clusters = append(clusters, s[start:i])
+			start = i
+			regional = 0
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		}
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// clusterWidth is the columns one grapheme takes. that's the width of its first
+// rune, except flags and emoji-style variations are always 2
+func clusterWidth(cluster string) int {
+	base, size := utf8.DecodeRuneInString(cluster)
+	rest := cluster[size:]
+	switch {
+	case isRegionalIndicator(base):
+		if utf8.RuneCountInString(cluster) > 1 {
+			return 2
+		}
+		return 1
+	case strings.ContainsRune(rest, emojiVariation):
+		return 2
+	}
+	return runeWidth(base)
+}
+
+// stringWidth is the terminal columns s takes. s shouldn't have escape codes in it,
+// displayWidth strips those first
+func stringWidth(s string) int {
+	width := 0
+	for _, cluster := range graphemes(s) {
+		width += clusterWidth(cluster)
+	}
+	return width
+}
+
+// splitWidth chops s into pieces at most maxWidth columns wide without
+// breaking up a grapheme. for words (and CJK text with no spaces) that don't
+// fit on a line at all
+func splitWidth(s string, maxWidth int) []string {
+	var pieces []string
+	var current strings.Builder
+	width := 0
+	for _, cluster := range graphemes(s) {
+		w := clusterWidth(cluster)
+		if width+w > maxWidth && width > 0 {
+			pieces = append(pieces, current.String())
+			current.Reset()
+			width = 0
+		}
+		current.WriteString(cluster)
+		width += w
+	}
+	if current.Len() > 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"ascii", "pikachu", 7},
+		{"empty", "", 0},
+		{"katakana", "ピカチュウ", 10},
+		{"hangul", "피카츄", 6},
+		{"chinese", "皮卡丘", 6},
+		{"precomposed accent", "Flabébé", 7},
+		{"combining accent", "Flabe\u0301be\u0301", 7},
+		{"emoji", "⚡", 2},
+		{"emoji with variation selector", "❤️", 2},
+		{"zwj family", "👩‍👩‍👧", 2},
+		{"flag", "🇯🇵", 2},
+		{"two flags", "🇯🇵🇫🇷", 4},
+		{"half blocks", "▀▄█", 3},
+		// the colorscript sprite lines debug_width.go was written for
+		{"ansi half blocks", "\x1b[38;5;16m▄\x1b[48;5;16m\x1b[38;5;226m▄\x1b[0m", 2},
+		{"ansi bold", "\033[1mPIKACHU\033[0m", 7},
+		{"ansi truecolor katakana", "\033[38;2;247;208;44mピカチュウ\033[0m", 10},
+	}
+
+	for _, tt := range tests {
+		if got := displayWidth(tt.input); got != tt.want {
+			t.Errorf("%s: displayWidth(%q) = %d, want %d", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"abc", []string{"a", "b", "c"}},
+		{"e\u0301a", []string{"e\u0301", "a"}},
+		{"👩‍👩‍👧!", []string{"👩‍👩‍👧", "!"}},
+		{"🇯🇵🇫🇷", []string{"🇯🇵", "🇫🇷"}},
+		{"ピカ", []string{"ピ", "カ"}},
+	}
+
+	for _, tt := range tests {
+		if got := graphemes(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("graphemes(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSplitWidth(t *testing.T) {
+	tests := []struct {
+		input    string
+		maxWidth int
+		want     []string
+	}{
+		{"abcdef", 4, []string{"abcd", "ef"}},
+		// a wide rune never gets cut in half, the line comes up short instead
+		{"ピカチュウ", 3, []string{"ピ", "カ", "チ", "ュ", "ウ"}},
+		{"ピカチュウ", 4, []string{"ピカ", "チュ", "ウ"}},
+		// the accent stays with its letter
+		{"abe\u0301cd", 3, []string{"abe\u0301", "cd"}},
+		{"a👩‍👩‍👧b", 2, []string{"a", "👩‍👩‍👧", "b"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitWidth(tt.input, tt.maxWidth); !slices.Equal(got, tt.want) {
+			t.Errorf("splitWidth(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestWordWrap(t *testing.T) {
+	tests := []struct {
+		input    string
+		maxWidth int
+		want     []string
+	}{
+		{"it keeps its tail raised", 10, []string{"it keeps", "its tail", "raised"}},
+		{"ピカチュウ ピカ", 6, []string{"ピカチ", "ュウ", "ピカ"}},
+		{"Flabe\u0301be\u0301 Flab\u00e9b\u00e9", 7, []string{"Flabe\u0301be\u0301", "Flab\u00e9b\u00e9"}},
+	}
+
+	for _, tt := range tests {
+		if got := wordWrap(tt.input, tt.maxWidth); !slices.Equal(got, tt.want) {
+			t.Errorf("wordWrap(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.want)
+		}
+	}
+}